@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/apidiff"
+)
+
+// ResultAPIChange is a change to the exported API of a Go package.
+type ResultAPIChange struct {
+	Package    string `json:"package"`
+	Message    string `json:"message"`
+	Compatible bool   `json:"compatible"`
+}
+
+// apiChangeLevel returns major when any change is incompatible, minor when there are only compatible changes and no
+// change otherwise.
+func apiChangeLevel(changes []ResultAPIChange) changeLevel {
+	level := changeLevelNoChange
+	for _, c := range changes {
+		if !c.Compatible {
+			return changeLevelMajor
+		}
+		level = changeLevelMinor
+	}
+	return level
+}
+
+// goAPIDiff compares the exported API of the Go packages under subdir in the local git checkout at dir between the
+// base and head refs. Main and internal packages are ignored.
+func goAPIDiff(ctx context.Context, dir, subdir, base, head string) ([]ResultAPIChange, error) {
+	oldPkgs, err := loadAPIAtRef(ctx, dir, subdir, base)
+	if err != nil {
+		return nil, err
+	}
+	newPkgs, err := loadAPIAtRef(ctx, dir, subdir, head)
+	if err != nil {
+		return nil, err
+	}
+	var result []ResultAPIChange
+	for _, name := range sortedKeys(oldPkgs) {
+		oldPkg := oldPkgs[name]
+		newPkg, ok := newPkgs[name]
+		if !ok {
+			result = append(result, ResultAPIChange{Package: oldPkg.PkgPath, Message: "package removed"})
+			continue
+		}
+		report := apidiff.Changes(oldPkg.Types, newPkg.Types)
+		for _, c := range report.Changes {
+			result = append(result, ResultAPIChange{
+				Package:    newPkg.PkgPath,
+				Message:    c.Message,
+				Compatible: c.Compatible,
+			})
+		}
+	}
+	for _, name := range sortedKeys(newPkgs) {
+		if _, ok := oldPkgs[name]; !ok {
+			result = append(result, ResultAPIChange{Package: newPkgs[name].PkgPath, Message: "package added", Compatible: true})
+		}
+	}
+	return result, nil
+}
+
+// listedPackage is the subset of "go list -json" output used by loadAPIAtRef.
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Export     string
+	DepOnly    bool
+	Module     *struct {
+		Path string
+	}
+}
+
+// loadAPIAtRef checks out ref in a temporary worktree and loads the packages under subdir from compiler export data.
+// The result is keyed by package path relative to its module so packages still match when the module path changes.
+func loadAPIAtRef(ctx context.Context, dir, subdir, ref string) (_ map[string]*apiPackage, errOut error) {
+	tmpDir, err := os.MkdirTemp("", "semver-next-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		errOut = errors.Join(errOut, os.RemoveAll(tmpDir))
+	}()
+	worktree := filepath.Join(tmpDir, "worktree")
+	_, err = runCommand(ctx, dir, "git", "worktree", "add", "--detach", worktree, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, err = runCommand(ctx, dir, "git", "worktree", "remove", "--force", worktree)
+		errOut = errors.Join(errOut, err)
+	}()
+	out, err := runCommand(ctx, filepath.Join(worktree, subdir), "go", "list", "-export", "-deps", "-json", "./...")
+	if err != nil {
+		return nil, err
+	}
+	var listed []listedPackage
+	exports := map[string]string{}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg listedPackage
+		err = decoder.Decode(&pkg)
+		if err != nil {
+			return nil, err
+		}
+		exports[pkg.ImportPath] = pkg.Export
+		if !pkg.DepOnly {
+			listed = append(listed, pkg)
+		}
+	}
+	imp := importer.ForCompiler(token.NewFileSet(), "gc", func(pkgPath string) (io.ReadCloser, error) {
+		export, ok := exports[pkgPath]
+		if !ok || export == "" {
+			return nil, fmt.Errorf("no export data for %s", pkgPath)
+		}
+		return os.Open(export)
+	})
+	result := map[string]*apiPackage{}
+	for _, pkg := range listed {
+		if pkg.Name == "main" || isInternalPackage(pkg.ImportPath) {
+			continue
+		}
+		typesPkg, err := imp.Import(pkg.ImportPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s at %s: %v", pkg.ImportPath, ref, err)
+		}
+		name := pkg.ImportPath
+		if pkg.Module != nil {
+			name = strings.TrimPrefix(name, pkg.Module.Path)
+		}
+		result[name] = &apiPackage{PkgPath: pkg.ImportPath, Types: typesPkg}
+	}
+	return result, nil
+}
+
+type apiPackage struct {
+	PkgPath string
+	Types   *types.Package
+}
+
+func isInternalPackage(pkgPath string) bool {
+	return strings.HasSuffix(pkgPath, "/internal") || strings.Contains(pkgPath, "/internal/")
+}
+
+func runCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %v\n%s", name, strings.Join(args, " "), err, stderr.String())
+	}
+	return out, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepo creates a git repository in a temp dir and returns a function that writes files and commits them,
+// returning the commit sha.
+func gitRepo(t *testing.T) (dir string, commit func(files map[string]string) string) {
+	t.Helper()
+	ctx := context.Background()
+	dir = t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := runCommand(ctx, dir, "git", args...)
+		require.NoError(t, err)
+		return string(out)
+	}
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "test")
+	git("config", "commit.gpgsign", "false")
+	return dir, func(files map[string]string) string {
+		t.Helper()
+		for name, content := range files {
+			filename := filepath.Join(dir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+			require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", "commit")
+		return git("rev-parse", "HEAD")[:40]
+	}
+}
+
+func Test_goAPIDiff(t *testing.T) {
+	ctx := context.Background()
+	dir, commit := gitRepo(t)
+	base := commit(map[string]string{
+		"go.mod":          "module example.com/foo\n\ngo 1.20\n",
+		"foo.go":          "package foo\n\nfunc Foo() {}\n\nfunc Bar() {}\n",
+		"internal/x/x.go": "package x\n\nfunc X() {}\n",
+		"cmd/foo/main.go": "package main\n\nfunc main() {}\n",
+		"sub/sub.go":      "package sub\n\nfunc Sub() {}\n",
+	})
+	minor := commit(map[string]string{
+		"foo.go":          "package foo\n\nfunc Foo() {}\n\nfunc Bar() {}\n\nfunc Baz() {}\n",
+		"internal/x/x.go": "package x\n",
+		"cmd/foo/main.go": "package main\n\nfunc Main() {}\n\nfunc main() {}\n",
+	})
+	major := commit(map[string]string{
+		"go.mod": "module example.com/foo/v2\n\ngo 1.20\n",
+		"foo.go": "package foo\n\nfunc Foo(int) {}\n\nfunc Baz() {}\n",
+	})
+
+	t.Run("compatible", func(t *testing.T) {
+		got, err := goAPIDiff(ctx, dir, "", base, minor)
+		require.NoError(t, err)
+		require.Equal(t, []ResultAPIChange{
+			{Package: "example.com/foo", Message: "Baz: added", Compatible: true},
+		}, got)
+		require.Equal(t, changeLevelMinor, apiChangeLevel(got))
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		got, err := goAPIDiff(ctx, dir, "", minor, major)
+		require.NoError(t, err)
+		require.Equal(t, []ResultAPIChange{
+			{Package: "example.com/foo/v2", Message: "Bar: removed"},
+			{Package: "example.com/foo/v2", Message: "Foo: changed from func() to func(int)"},
+		}, got)
+		require.Equal(t, changeLevelMajor, apiChangeLevel(got))
+	})
+
+	t.Run("subdir", func(t *testing.T) {
+		got, err := goAPIDiff(ctx, dir, "sub", base, major)
+		require.NoError(t, err)
+		require.Empty(t, got)
+		require.Equal(t, changeLevelNoChange, apiChangeLevel(got))
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// cherryPickTrailerRegexp matches the line "git cherry-pick -x" adds to commit messages.
+var cherryPickTrailerRegexp = regexp.MustCompile(`(?m)^\(cherry picked from commit ([0-9a-f]{40})\)\s*$`)
+
+// backportOfRegexp matches "Backport of #123" in the title or body of a backport pull request.
+var backportOfRegexp = regexp.MustCompile(`(?i)\bbackport of #(\d+)\b`)
+
+// backportTitleRegexp matches the "[Backport release/1.4.x]" title prefix backport bots use.
+var backportTitleRegexp = regexp.MustCompile(`(?i)^\s*\[backport\b[^\]]*\]`)
+
+// backportFromRegexp matches "Backport abc1234 from #123" in the body of a pull request with a backport title.
+var backportFromRegexp = regexp.MustCompile(`(?i)\bbackport [0-9a-f]{7,40} from #(\d+)\b`)
+
+// cherryPickedFrom returns the sha from a commit message's cherry-pick trailer or "" when it has none.
+func cherryPickedFrom(message string) string {
+	match := cherryPickTrailerRegexp.FindAllStringSubmatch(message, -1)
+	if match == nil {
+		return ""
+	}
+	return match[len(match)-1][1]
+}
+
+// backportOf returns the number of the pull request that pull backports or 0 when it isn't a backport.
+func backportOf(pull ResultPull) int {
+	matches := [][]string{
+		backportOfRegexp.FindStringSubmatch(pull.Title),
+		backportOfRegexp.FindStringSubmatch(pull.Body),
+	}
+	if backportTitleRegexp.MatchString(pull.Title) {
+		matches = append(matches, backportFromRegexp.FindStringSubmatch(pull.Body))
+	}
+	for _, match := range matches {
+		if match == nil {
+			continue
+		}
+		number, err := strconv.Atoi(match[1])
+		if err == nil && number != pull.Number {
+			return number
+		}
+	}
+	return 0
+}
+
+// resolveBackports attributes backported changes to a single pull request in commitPulls so they aren't counted
+// twice:
+//
+//   - A backport pull request's original is removed from the commits it shares with the backport, so the backport's
+//     labels decide the level.
+//   - A cherry-picked commit without pull requests of its own gets the pull requests of the commit it was picked from,
+//     or none when that commit isn't in the repository.
+//   - A cherry-picked commit whose original is also in commits drops the pull requests it shares with the original
+//     because the original already accounts for them. Pull requests of its own are kept.
+//
+// It returns the sha each cherry-picked commit was picked from keyed by the cherry-pick's sha.
+func resolveBackports(ctx context.Context, gh wrapper, owner, repo string, commits []repoCommit, commitPulls map[string][]ResultPull) (map[string]string, error) {
+	inRange := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		inRange[c.Sha] = true
+	}
+	cherryPicks := map[string]string{}
+	for _, c := range commits {
+		from := cherryPickedFrom(c.Message)
+		if from == "" {
+			continue
+		}
+		cherryPicks[c.Sha] = from
+		switch {
+		case inRange[from]:
+			commitPulls[c.Sha] = dropSharedPulls(commitPulls[c.Sha], commitPulls[from])
+		case len(commitPulls[c.Sha]) == 0:
+			// the original may have been rewritten before it was pushed or only exist in a fork
+			pulls, err := gh.ListPullRequestsWithCommit(ctx, owner, repo, from)
+			if err != nil && !errors.Is(err, errCommitNotFound) {
+				return nil, err
+			}
+			commitPulls[c.Sha] = pulls
+		}
+	}
+	for sha, pulls := range commitPulls {
+		commitPulls[sha] = dropBackportedPulls(pulls)
+	}
+	return cherryPicks, nil
+}
+
+// dropSharedPulls returns the pull requests in pulls that aren't in others.
+func dropSharedPulls(pulls, others []ResultPull) []ResultPull {
+	shared := make(map[int]bool, len(others))
+	for _, p := range others {
+		shared[p.Number] = true
+	}
+	var result []ResultPull
+	for _, p := range pulls {
+		if !shared[p.Number] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// dropBackportedPulls sets BackportOf on backport pull requests and removes the pull requests they backport.
+func dropBackportedPulls(pulls []ResultPull) []ResultPull {
+	backported := map[int]bool{}
+	for i := range pulls {
+		pulls[i].BackportOf = backportOf(pulls[i])
+		if pulls[i].BackportOf != 0 {
+			backported[pulls[i].BackportOf] = true
+		}
+	}
+	if len(backported) == 0 {
+		return pulls
+	}
+	result := make([]ResultPull, 0, len(pulls))
+	for _, p := range pulls {
+		if !backported[p.Number] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cherryPickedFrom(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	require.Equal(t, sha, cherryPickedFrom("fix a bug\n\n(cherry picked from commit "+sha+")\n"))
+	require.Equal(t, "", cherryPickedFrom("fix a bug\n\ncherry picked from commit "+sha))
+	require.Equal(t, "", cherryPickedFrom("fix a bug"))
+}
+
+func Test_backportOf(t *testing.T) {
+	for _, td := range []struct {
+		pull ResultPull
+		want int
+	}{
+		{pull: ResultPull{Number: 20, Title: "[Backport release/1.4.x] Fix a bug", Body: "Backport abc1234 from #12."}, want: 12},
+		{pull: ResultPull{Number: 20, Title: "[release/1.4.x] Fix a bug", Body: "# Description\nBackport of #12 to `release/1.4.x`."}, want: 12},
+		{pull: ResultPull{Number: 20, Title: "Fix a bug (Backport of #12)"}, want: 12},
+		{pull: ResultPull{Number: 20, Title: "Fix a bug", Body: "Backport abc1234 from #12."}},
+		{pull: ResultPull{Number: 20, Title: "Fix backport bot crash, see #45"}},
+		{pull: ResultPull{Number: 20, Title: "Fix a bug", Body: "Fixes #12"}},
+		{pull: ResultPull{Number: 20, Title: "Add backport workflow"}},
+	} {
+		require.Equal(t, td.want, backportOf(td.pull), td.pull.Title)
+	}
+}
+
+func Test_next_backports(t *testing.T) {
+	ctx := context.Background()
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha2 := "2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha3 := "3aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	picked := "9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	gh := &wrapperStub{
+		compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+			return []string{sha1, sha2, sha3}, nil
+		},
+		commitMessages: map[string]string{
+			sha2: "fix a bug\n\n(cherry picked from commit " + picked + ")",
+			sha3: "fix it again\n\n(cherry picked from commit " + sha1 + ")",
+		},
+		listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+			{
+				owner: "willabides", repo: "semver-next", sha: sha1,
+				result: []ResultPull{
+					{Number: 10, Title: "Add a feature", Labels: []string{"enhancement"}},
+					{Number: 20, Title: "[Backport release/1.4.x] Add a feature", Body: "Backport of #10", Labels: []string{"bug"}},
+				},
+			},
+			{owner: "willabides", repo: "semver-next", sha: sha2},
+			{
+				owner: "willabides", repo: "semver-next", sha: sha3,
+				result: []ResultPull{
+					{Number: 20, Title: "[Backport release/1.4.x] Add a feature", Body: "Backport of #10", Labels: []string{"bug"}},
+					{Number: 30, Labels: []string{"breaking"}},
+				},
+			},
+			{
+				owner: "willabides", repo: "semver-next", sha: picked,
+				result: []ResultPull{{Number: 11, Labels: []string{"bug"}}},
+			},
+		}),
+	}
+	got, err := next(ctx, nextOptions{
+		gh:   gh,
+		repo: "willabides/semver-next",
+		base: "v1.4.0",
+		head: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0", got.NextVersion)
+	require.Equal(t, []ResultCommit{
+		{
+			Sha:         sha1,
+			ChangeLevel: changeLevelPatch,
+			Pulls: []ResultPull{
+				{Number: 20, Title: "[Backport release/1.4.x] Add a feature", Body: "Backport of #10", Labels: []string{"bug"}, ChangeLevel: changeLevelPatch, BackportOf: 10},
+			},
+		},
+		{
+			Sha:              sha2,
+			ChangeLevel:      changeLevelPatch,
+			Pulls:            []ResultPull{{Number: 11, Labels: []string{"bug"}, ChangeLevel: changeLevelPatch}},
+			CherryPickedFrom: picked,
+		},
+		{
+			Sha:              sha3,
+			ChangeLevel:      changeLevelMajor,
+			Pulls:            []ResultPull{{Number: 30, Labels: []string{"breaking"}, ChangeLevel: changeLevelMajor}},
+			CherryPickedFrom: sha1,
+		},
+	}, got.Commits)
+}
+
+func Test_next_cherryPickNotInRepo(t *testing.T) {
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	picked := "9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	gh := &wrapperStub{
+		compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+			return []string{sha1}, nil
+		},
+		commitMessages: map[string]string{
+			sha1: "fix a bug\n\n(cherry picked from commit " + picked + ")",
+		},
+		listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+			{owner: "willabides", repo: "semver-next", sha: sha1},
+			{owner: "willabides", repo: "semver-next", sha: picked, err: errCommitNotFound},
+		}),
+	}
+	got, err := next(context.Background(), nextOptions{
+		gh:   gh,
+		repo: "willabides/semver-next",
+		base: "v1.4.0",
+		head: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0", got.NextVersion)
+	require.Equal(t, []ResultCommit{{Sha: sha1, CherryPickedFrom: picked}}, got.Commits)
+}
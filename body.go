@@ -0,0 +1,85 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	checkedBoxRegexp      = regexp.MustCompile(`^\s*[-*]\s+\[[xX]\]\s+(.+?)\s*$`)
+	breakingChangeRegexp  = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+	fencedBlockEndRegexp  = regexp.MustCompile("^\\s*```\\s*$")
+	fencedBlockOpenRegexp = regexp.MustCompile("^\\s*```\\s*([^\\s`]*)\\s*$")
+)
+
+// bodyRules determine a change level and release note from a pull request's body.
+type bodyRules struct {
+	// checkboxes maps lowercase checkbox text to a change level. A checked box matches when its text starts with
+	// the key.
+	checkboxes map[string]changeLevel
+
+	// breakingChange makes a line starting with "BREAKING CHANGE:" a major change.
+	breakingChange bool
+
+	// releaseNoteBlock is the info string of fenced code blocks containing release notes. e.g. "release-note"
+	releaseNoteBlock string
+}
+
+// level returns the highest change level found in body and whether any was found.
+func (b *bodyRules) level(body string) (changeLevel, bool) {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	level := changeLevelNoChange
+	found := false
+	if b.breakingChange && breakingChangeRegexp.MatchString(body) {
+		level, found = changeLevelMajor, true
+	}
+	for _, line := range strings.Split(body, "\n") {
+		match := checkedBoxRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.ToLower(match[1])
+		for prefix, l := range b.checkboxes {
+			if !strings.HasPrefix(text, prefix) {
+				continue
+			}
+			found = true
+			if l > level {
+				level = l
+			}
+		}
+	}
+	return level, found
+}
+
+// releaseNote returns the contents of the fenced release note blocks in body.
+func (b *bodyRules) releaseNote(body string) string {
+	if b.releaseNoteBlock == "" {
+		return ""
+	}
+	var notes []string
+	var current []string
+	inBlock, inOtherBlock := false, false
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		switch {
+		case inBlock && fencedBlockEndRegexp.MatchString(line):
+			notes = append(notes, strings.TrimSpace(strings.Join(current, "\n")))
+			current, inBlock = nil, false
+		case inBlock:
+			current = append(current, line)
+		case inOtherBlock:
+			inOtherBlock = !fencedBlockEndRegexp.MatchString(line)
+		default:
+			match := fencedBlockOpenRegexp.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			if strings.EqualFold(match[1], b.releaseNoteBlock) {
+				inBlock = true
+			} else {
+				inOtherBlock = true
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(notes, "\n\n"))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bodyRules(t *testing.T) {
+	rules := &bodyRules{
+		checkboxes: map[string]changeLevel{
+			"breaking change": changeLevelMajor,
+			"new feature":     changeLevelMinor,
+			"bug fix":         changeLevelPatch,
+		},
+		breakingChange:   true,
+		releaseNoteBlock: "release-note",
+	}
+
+	t.Run("checkboxes", func(t *testing.T) {
+		body := "## Type of change\r\n\r\n" +
+			"- [x] Bug fix (non-breaking change which fixes an issue)\r\n" +
+			"- [X] New feature\r\n" +
+			"- [ ] Breaking change\r\n"
+		level, ok := rules.level(body)
+		require.True(t, ok)
+		require.Equal(t, changeLevelMinor, level)
+	})
+
+	t.Run("breaking change paragraph", func(t *testing.T) {
+		level, ok := rules.level("Some text.\n\nBREAKING CHANGE: Foo was removed.\n")
+		require.True(t, ok)
+		require.Equal(t, changeLevelMajor, level)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		_, ok := rules.level("- [ ] Breaking change\nnot a BREAKING CHANGE: at line start\n")
+		require.False(t, ok)
+	})
+
+	t.Run("release note", func(t *testing.T) {
+		body := "Fixes #1\n\n" +
+			"```go\nfmt.Println(\"not a note\")\n```\n\n" +
+			"```release-note\r\nFoo no longer panics.\r\n```\n\n" +
+			"```release-note\nBar is faster.\n```\n"
+		require.Equal(t, "Foo no longer panics.\n\nBar is faster.", rules.releaseNote(body))
+		require.Empty(t, (&bodyRules{}).releaseNote(body))
+	})
+
+	t.Run("pull rules", func(t *testing.T) {
+		major := changeLevelMajor
+		got := (&pullRules{body: rules}).evaluatePull(ResultPull{
+			Number: 1,
+			Labels: []string{"bug"},
+			Body:   "BREAKING CHANGE: Foo was removed.\n```release-note\nFoo was removed.\n```",
+		})
+		require.Equal(t, ResultPull{
+			Number:          1,
+			Labels:          []string{"bug"},
+			ChangeLevel:     changeLevelMajor,
+			BodyChangeLevel: &major,
+			ReleaseNote:     "Foo was removed.",
+			Body:            "BREAKING CHANGE: Foo was removed.\n```release-note\nFoo was removed.\n```",
+		}, got)
+	})
+	t.Run("title-first", func(t *testing.T) {
+		r := &pullRules{titleMode: titleModeTitleFirst, body: rules}
+		// the title overrides the labels
+		got := r.evaluatePull(ResultPull{Title: "docs: fix typo", Labels: []string{"enhancement"}})
+		require.Equal(t, changeLevelNoChange, got.ChangeLevel)
+		// the body only raises the level
+		got = r.evaluatePull(ResultPull{Title: "fix: remove Foo", Labels: []string{"enhancement"}, Body: "BREAKING CHANGE: Foo was removed."})
+		require.Equal(t, changeLevelMajor, got.ChangeLevel)
+		got = r.evaluatePull(ResultPull{Title: "feat: add Bar", Body: "- [x] Bug fix"})
+		require.Equal(t, changeLevelMinor, got.ChangeLevel)
+	})
+}
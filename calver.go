@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// calverCounters are the format tokens for the counter that increments for releases with the same date.
+var calverCounters = map[string]bool{"MICRO": true, "PATCH": true}
+
+// calverDateTokens are the date format tokens from https://calver.org.
+var calverDateTokens = map[string]func(t time.Time) string{
+	"YYYY": func(t time.Time) string { return strconv.Itoa(t.Year()) },
+	"YY":   func(t time.Time) string { return strconv.Itoa(t.Year() - 2000) },
+	"0Y":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()-2000) },
+	"MM":   func(t time.Time) string { return strconv.Itoa(int(t.Month())) },
+	"0M":   func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) },
+	"WW":   func(t time.Time) string { _, w := t.ISOWeek(); return strconv.Itoa(w) },
+	"0W":   func(t time.Time) string { _, w := t.ISOWeek(); return fmt.Sprintf("%02d", w) },
+	"DD":   func(t time.Time) string { return strconv.Itoa(t.Day()) },
+	"0D":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+}
+
+// calverScheme is calendar versioning with a format like "YYYY.MM.PATCH" or "YY.0M.MICRO". Releases get the current
+// UTC date and a counter that resets to 0 when the date changes.
+type calverScheme struct {
+	format []string
+	now    func() time.Time
+}
+
+// newCalverScheme returns a calverScheme for format. The format is dot-separated date tokens ending with MICRO or
+// PATCH. now defaults to time.Now.
+func newCalverScheme(format string, now func() time.Time) (*calverScheme, error) {
+	if format == "" {
+		return nil, fmt.Errorf("calver scheme requires a format like calver:YYYY.0M.MICRO")
+	}
+	tokens := strings.Split(format, ".")
+	for i, token := range tokens {
+		last := i == len(tokens)-1
+		if last && calverCounters[token] {
+			continue
+		}
+		if last {
+			return nil, fmt.Errorf("calver format %q must end with MICRO or PATCH", format)
+		}
+		if calverDateTokens[token] == nil {
+			return nil, fmt.Errorf("calver format %q has unknown token %q", format, token)
+		}
+	}
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("calver format %q has no date tokens", format)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &calverScheme{format: tokens, now: now}, nil
+}
+
+func (s *calverScheme) parse(v string) (schemeVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) != len(s.format) {
+		return nil, fmt.Errorf("%q does not match calver format %s", v, strings.Join(s.format, "."))
+	}
+	for _, part := range parts {
+		_, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q does not match calver format %s", v, strings.Join(s.format, "."))
+		}
+	}
+	counter, _ := strconv.Atoi(parts[len(parts)-1])
+	return &calverVersion{scheme: s, date: parts[:len(parts)-1], counter: counter}, nil
+}
+
+func (s *calverScheme) at(t time.Time) versionScheme {
+	return &calverScheme{format: s.format, now: func() time.Time { return t }}
+}
+
+type calverVersion struct {
+	scheme  *calverScheme
+	date    []string
+	counter int
+}
+
+func (v *calverVersion) bump(level changeLevel) schemeVersion {
+	if level == changeLevelNoChange {
+		return v
+	}
+	now := v.scheme.now().UTC()
+	date := make([]string, len(v.date))
+	sameDate := true
+	for i := range date {
+		date[i] = calverDateTokens[v.scheme.format[i]](now)
+		if !calverPartEqual(date[i], v.date[i]) {
+			sameDate = false
+		}
+	}
+	next := &calverVersion{scheme: v.scheme, date: date}
+	if sameDate {
+		next.counter = v.counter + 1
+	}
+	return next
+}
+
+func (v *calverVersion) compare(other schemeVersion) int {
+	o := other.(*calverVersion)
+	for i := range v.date {
+		a, _ := strconv.Atoi(v.date[i])
+		b, _ := strconv.Atoi(o.date[i])
+		if c := compareInts(a, b); c != 0 {
+			return c
+		}
+	}
+	return compareInts(v.counter, o.counter)
+}
+
+func (v *calverVersion) prerelease() bool {
+	return false
+}
+
+// calverPartEqual compares numeric version parts, ignoring zero-padding.
+func calverPartEqual(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	return errA == nil && errB == nil && x == y
+}
+
+func (v *calverVersion) String() string {
+	return strings.Join(append(append([]string{}, v.date...), strconv.Itoa(v.counter)), ".")
+}
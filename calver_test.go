@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_calverScheme(t *testing.T) {
+	now := func() time.Time { return time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC) }
+
+	for _, td := range []struct {
+		format string
+		prev   string
+		level  changeLevel
+		want   string
+	}{
+		{format: "YYYY.MM.PATCH", prev: "2024.3.4", level: changeLevelPatch, want: "2024.3.5"},
+		{format: "YYYY.MM.PATCH", prev: "2024.2.4", level: changeLevelPatch, want: "2024.3.0"},
+		{format: "YYYY.MM.PATCH", prev: "v2024.2.4", level: changeLevelMajor, want: "2024.3.0"},
+		{format: "YYYY.MM.PATCH", prev: "2024.2.4", level: changeLevelNoChange, want: "2024.2.4"},
+		{format: "YY.0M.MICRO", prev: "24.03.0", level: changeLevelMinor, want: "24.03.1"},
+		{format: "YY.0M.MICRO", prev: "23.12.7", level: changeLevelPatch, want: "24.03.0"},
+		{format: "YYYY.0M.0D.MICRO", prev: "2024.03.05.1", level: changeLevelPatch, want: "2024.03.05.2"},
+	} {
+		scheme, err := newCalverScheme(td.format, now)
+		require.NoError(t, err)
+		prev, err := scheme.parse(td.prev)
+		require.NoError(t, err)
+		require.Equal(t, td.want, prev.bump(td.level).String(), "%s %s", td.format, td.prev)
+	}
+
+	t.Run("invalid formats", func(t *testing.T) {
+		for _, format := range []string{"", "MICRO", "YYYY.MM", "YYYY.XX.MICRO"} {
+			_, err := newCalverScheme(format, nil)
+			require.Error(t, err, format)
+		}
+	})
+
+	t.Run("invalid versions", func(t *testing.T) {
+		scheme, err := newCalverScheme("YYYY.MM.PATCH", nil)
+		require.NoError(t, err)
+		for _, v := range []string{"2024.3", "2024.3.1.1", "2024.march.1", "1.2.3-rc1"} {
+			_, err = scheme.parse(v)
+			require.Error(t, err, v)
+		}
+	})
+}
+
+func Test_next_calver(t *testing.T) {
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	now := func() time.Time { return time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC) }
+	scheme, err := newCalverScheme("YYYY.0M.MICRO", now)
+	require.NoError(t, err)
+	opts := nextOptions{repo: "willabides/semver-next", base: "v2024.03.2", head: "main", scheme: scheme}
+
+	opts.gh = labeledPullStub(t, sha1, "breaking")
+	got, err := next(context.Background(), opts)
+	require.NoError(t, err)
+	require.Equal(t, "2024.03.2", got.PreviousVersion)
+	require.Equal(t, "2024.03.3", got.NextVersion)
+
+	opts.gh = labeledPullStub(t, sha1, "semver:none")
+	got, err = next(context.Background(), opts)
+	require.NoError(t, err)
+	require.Equal(t, "2024.03.2", got.NextVersion)
+
+	opts.gh = labeledPullStub(t, sha1, "bug")
+	opts.goModule = goModuleCheckWarn
+	_, err = next(context.Background(), opts)
+	require.EqualError(t, err, "checking the go module requires the semver version scheme")
+}
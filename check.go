@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type checkCmd struct {
+	Repo     string `kong:"arg,required,help=${repo_help}"`
+	Ref      string `kong:"short=r,help=${check_ref_help}"`
+	PrevRef  string `kong:"short=p,help=${check_prev_ref_help}"`
+	Pull     int    `kong:"help=${pull_help}"`
+	Checkout string `kong:"type=existingdir,help=${check_checkout_help},default=."`
+	Json     bool   `kong:"help=Output in JSON format"`
+}
+
+func (c *checkCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	res, err := check(ctx, checkOptions{
+		gh:       gh,
+		rules:    cfg.pullRules(),
+		repo:     c.Repo,
+		base:     c.PrevRef,
+		head:     c.Ref,
+		pull:     c.Pull,
+		checkout: c.Checkout,
+	})
+	if err != nil {
+		return err
+	}
+	if c.Json {
+		err = printJSON(res)
+		if err != nil {
+			return err
+		}
+	}
+	return res.err()
+}
+
+// CheckResult compares the labeled change level to the level of changes in exported Go APIs.
+type CheckResult struct {
+	Pull               int               `json:"pull,omitempty"`
+	LabeledChangeLevel changeLevel       `json:"labeled_change_level"`
+	APIChangeLevel     changeLevel       `json:"api_change_level"`
+	APIChanges         []ResultAPIChange `json:"api_changes,omitempty"`
+	// Mislabeled holds the API changes that exceed LabeledChangeLevel.
+	Mislabeled []ResultAPIChange `json:"mislabeled,omitempty"`
+}
+
+func (r *CheckResult) err() error {
+	if len(r.Mislabeled) == 0 {
+		return nil
+	}
+	subject := "changes"
+	if r.Pull != 0 {
+		subject = fmt.Sprintf("pull request #%d", r.Pull)
+	}
+	msgs := make([]string, len(r.Mislabeled))
+	for i, c := range r.Mislabeled {
+		msgs[i] = fmt.Sprintf("%s: %s", c.Package, c.Message)
+	}
+	return fmt.Errorf("%s labeled %s has %s API changes:\n%s",
+		subject, r.LabeledChangeLevel, r.APIChangeLevel, strings.Join(msgs, "\n"))
+}
+
+type checkOptions struct {
+	gh       wrapper
+	repo     string
+	base     string
+	head     string
+	pull     int
+	checkout string
+	rules    *pullRules
+}
+
+// check compares the exported Go API in the local checkout at base and head to the labeled change level. When pull
+// is set, the labeled level comes from that pull request and base and head default to its base and head commits.
+// Otherwise, it comes from the merged pull requests between base and head.
+func check(ctx context.Context, opts checkOptions) (*CheckResult, error) {
+	owner, repo, err := splitRepo(opts.repo)
+	if err != nil {
+		return nil, err
+	}
+	base, head := opts.base, opts.head
+	result := CheckResult{Pull: opts.pull}
+	if opts.pull != 0 {
+		pull, err := opts.gh.GetPullRequest(ctx, owner, repo, opts.pull)
+		if err != nil {
+			return nil, err
+		}
+		labeled := opts.rules.evaluate([]ResultPull{pull.resultPull()})[0]
+		if !labeled.hasLevel() {
+			return nil, fmt.Errorf("pull request #%d has no semver labels", pull.Number)
+		}
+		result.LabeledChangeLevel = labeled.ChangeLevel
+		if base == "" {
+			base = pull.BaseSha
+		}
+		if head == "" {
+			head = pull.HeadSha
+		}
+		// compare from where the pull request branched so changes on the base branch aren't attributed to it
+		out, err := runCommand(ctx, opts.checkout, "git", "merge-base", base, head)
+		if err != nil {
+			return nil, err
+		}
+		base = strings.TrimSpace(string(out))
+	} else {
+		if base == "" || head == "" {
+			return nil, fmt.Errorf("--prev-ref and --ref are required without --pull")
+		}
+		commits, _, err := compareCommits(ctx, opts.gh, owner, repo, base, head, nil, opts.rules)
+		if err != nil {
+			return nil, err
+		}
+		result.LabeledChangeLevel = commitsChangeLevel(commits)
+	}
+	result.APIChanges, err = goAPIDiff(ctx, opts.checkout, "", base, head)
+	if err != nil {
+		return nil, err
+	}
+	result.APIChangeLevel = apiChangeLevel(result.APIChanges)
+	for _, c := range result.APIChanges {
+		if apiChangeLevel([]ResultAPIChange{c}) > result.LabeledChangeLevel {
+			result.Mislabeled = append(result.Mislabeled, c)
+		}
+	}
+	return &result, nil
+}
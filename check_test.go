@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_check(t *testing.T) {
+	ctx := context.Background()
+	dir, commit := gitRepo(t)
+	base := commit(map[string]string{
+		"go.mod": "module example.com/foo\n\ngo 1.20\n",
+		"foo.go": "package foo\n\nfunc Foo() {}\n",
+	})
+	added := commit(map[string]string{
+		"foo.go": "package foo\n\nfunc Foo() {}\n\nfunc Bar() {}\n",
+	})
+
+	pullStub := func(labels ...string) *wrapperStub {
+		return (&wrapperStub{}).withPullRequest(t, pullRequest{Number: 12, Labels: labels, BaseSha: base, HeadSha: added})
+	}
+
+	t.Run("pull labeled patch adds identifiers", func(t *testing.T) {
+		got, err := check(ctx, checkOptions{
+			gh:       pullStub("bug"),
+			repo:     "willabides/semver-next",
+			pull:     12,
+			checkout: dir,
+		})
+		require.NoError(t, err)
+		addedBar := ResultAPIChange{Package: "example.com/foo", Message: "Bar: added", Compatible: true}
+		require.Equal(t, &CheckResult{
+			Pull:               12,
+			LabeledChangeLevel: changeLevelPatch,
+			APIChangeLevel:     changeLevelMinor,
+			APIChanges:         []ResultAPIChange{addedBar},
+			Mislabeled:         []ResultAPIChange{addedBar},
+		}, got)
+		require.EqualError(t, got.err(), "pull request #12 labeled patch has minor API changes:\nexample.com/foo: Bar: added")
+	})
+
+	t.Run("pull labeled minor", func(t *testing.T) {
+		got, err := check(ctx, checkOptions{
+			gh:       pullStub("enhancement"),
+			repo:     "willabides/semver-next",
+			pull:     12,
+			checkout: dir,
+		})
+		require.NoError(t, err)
+		require.Empty(t, got.Mislabeled)
+		require.NoError(t, got.err())
+	})
+
+	t.Run("pull without labels", func(t *testing.T) {
+		_, err := check(ctx, checkOptions{
+			gh:       pullStub("something else"),
+			repo:     "willabides/semver-next",
+			pull:     12,
+			checkout: dir,
+		})
+		require.EqualError(t, err, "pull request #12 has no semver labels")
+	})
+
+	t.Run("merged pulls", func(t *testing.T) {
+		gh := &wrapperStub{
+			compareCommits: func(ctx context.Context, owner, repo, b, h string) ([]string, error) {
+				return []string{added}, nil
+			},
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{
+					owner: "willabides", repo: "semver-next", sha: added,
+					result: []ResultPull{{Number: 1, Labels: []string{"semver:none"}}},
+				},
+			}),
+		}
+		got, err := check(ctx, checkOptions{
+			gh:       gh,
+			repo:     "willabides/semver-next",
+			base:     base,
+			head:     added,
+			checkout: dir,
+		})
+		require.NoError(t, err)
+		require.Equal(t, changeLevelNoChange, got.LabeledChangeLevel)
+		require.EqualError(t, got.err(), "changes labeled no change has minor API changes:\nexample.com/foo: Bar: added")
+	})
+}
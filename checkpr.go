@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+type checkPRCmd struct {
+	Repo        string `kong:"arg,required,help=${repo_help}"`
+	Number      int    `kong:"arg,required,help=${pull_number_help}"`
+	PrevRef     string `kong:"short=p,help=${pull_prev_ref_help}"`
+	PrevVersion string `kong:"prev-version,short=v,help=${prev_version_help}"`
+	Json        bool   `kong:"help=Output in JSON format"`
+}
+
+func (c *checkPRCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	res, err := predictPull(ctx, pullOptions{
+		gh:                  gh,
+		repo:                c.Repo,
+		number:              c.Number,
+		base:                c.PrevRef,
+		prevVersion:         c.PrevVersion,
+		rules:               cfg.pullRules(),
+		maintenanceBranches: cfg.maintenanceBranches,
+	})
+	if err != nil {
+		return err
+	}
+	if c.Json {
+		err = printJSON(res)
+		if err != nil {
+			return err
+		}
+	}
+	if len(res.Problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(res.Problems, "\n"))
+	}
+	if !c.Json {
+		fmt.Println(res.summary())
+	}
+	return nil
+}
+
+// PullResult is the Result of releasing the changes since the previous release along with a pull request.
+type PullResult struct {
+	Pull    ResultPull `json:"pull"`
+	HeadSha string     `json:"head_sha"`
+	// Problems describe missing or conflicting labels on the pull request.
+	Problems []string `json:"problems,omitempty"`
+	Result
+}
+
+func (r *PullResult) summary() string {
+	change := fmt.Sprintf("is a %s change", r.Pull.ChangeLevel)
+	if r.Pull.ChangeLevel == changeLevelNoChange {
+		change = "doesn't change the version"
+	}
+	return fmt.Sprintf("#%d %s. If it is merged, the next release will be %s (previous release %s).",
+		r.Pull.Number, change, r.NextVersion, r.PreviousVersion)
+}
+
+type pullOptions struct {
+	gh          wrapper
+	repo        string
+	number      int
+	base        string
+	prevVersion string
+	rules       *pullRules
+
+	// maintenanceBranches detect maintenance branches from the pull request's base branch. nil uses
+	// defaultMaintenanceBranches.
+	maintenanceBranches []*regexp.Regexp
+}
+
+// pullLabelProblems describes missing or conflicting labels on a pull request that has been evaluated by rules.
+func pullLabelProblems(pull ResultPull, rules *pullRules) []string {
+	if !pull.hasLevel() && rules != nil && rules.titleMode != "" {
+		return []string{fmt.Sprintf(
+			"pull request #%d has no semver labels or conventional commit title. Add one of semver:major, "+
+				`semver:minor, semver:patch or semver:none, or use a title like "fix: ..." or "feat: ...".`,
+			pull.Number,
+		)}
+	}
+	if !pull.hasLevel() {
+		return []string{fmt.Sprintf(
+			"pull request #%d has no semver labels. Add one of semver:major, semver:minor, semver:patch or semver:none.",
+			pull.Number,
+		)}
+	}
+	conflict := labelConflict(pull, rules)
+	if conflict == "" {
+		return nil
+	}
+	return []string{conflict + ". Remove all but one."}
+}
+
+// predictPull computes the Result of releasing the pull request's base branch with the pull request merged. The
+// previous release defaults to the highest semver tag in the repository, or in the release line when the base branch
+// is a maintenance branch. Missing labels on pull requests that are already merged are not reported.
+func predictPull(ctx context.Context, opts pullOptions) (*PullResult, error) {
+	owner, repo, err := splitRepo(opts.repo)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := opts.gh.GetPullRequest(ctx, owner, repo, opts.number)
+	if err != nil {
+		return nil, err
+	}
+	// a merged pull request is already in its base branch, so it would be counted twice
+	if pr.State != "open" {
+		return nil, fmt.Errorf("pull request #%d is %s; only open pull requests can be checked", pr.Number, pr.State)
+	}
+	pull := opts.rules.evaluate([]ResultPull{pr.resultPull()})[0]
+
+	line := findMaintenanceLine(pr.BaseRef, opts.maintenanceBranches)
+	base, prevVersion := opts.base, opts.prevVersion
+	if base == "" {
+		tags, err := opts.gh.ListTags(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		if line != nil {
+			base, err = line.latestTag(tags)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			base, _ = latestPackageTag(tags, "")
+			if base == "" {
+				return nil, fmt.Errorf("no semver tags found in %s; set --prev-ref", opts.repo)
+			}
+		}
+	}
+	if prevVersion == "" {
+		prevVersion = base
+	}
+	prev, err := semver.NewVersion(prevVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous version %q: %v", prevVersion, err)
+	}
+
+	baseComp, err := opts.gh.CompareCommits(ctx, owner, repo, base, pr.BaseRef)
+	if err != nil {
+		return nil, err
+	}
+	err = checkCompareStatus(base, pr.BaseRef, baseComp.Status)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := fetchCommits(ctx, opts.gh, owner, repo, baseComp.Commits, opts.rules)
+	if err != nil {
+		return nil, err
+	}
+	commits = append(commits, ResultCommit{
+		Sha:         pr.HeadSha,
+		Pulls:       []ResultPull{pull},
+		ChangeLevel: pull.ChangeLevel,
+	})
+	result := newResult(semverVersion{prev}, commits)
+	result.CompareStatus = baseComp.Status
+	applyOverrides(result)
+	problems := pullLabelProblems(pull, opts.rules)
+	if line != nil {
+		result.MaintenanceLine = line.String()
+		if problem := line.levelProblem(result); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	applyBump(result, semverVersion{prev}, changeLevelNoChange, changeLevelMajor)
+	return &PullResult{
+		Pull:     pull,
+		HeadSha:  pr.HeadSha,
+		Problems: problems,
+		Result:   *result,
+	}, nil
+}
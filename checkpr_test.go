@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_predictPull(t *testing.T) {
+	ctx := context.Background()
+
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	headSha := "9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	stub := func(labels ...string) *wrapperStub {
+		return labeledPullStub(t, sha1, "bug").
+			withTags("v1.1.0", "v1.2.0", "other/v3.0.0").
+			withPullRequest(t, pullRequest{Number: 12, Labels: labels, State: "open", BaseRef: "main", HeadSha: headSha})
+	}
+
+	t.Run("minor", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("Enhancement", "documentation"),
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.NoError(t, err)
+		require.Empty(t, got.Problems)
+		require.Equal(t, ResultPull{Number: 12, Labels: []string{"enhancement"}, ChangeLevel: changeLevelMinor}, got.Pull)
+		require.Equal(t, "1.2.0", got.PreviousVersion)
+		require.Equal(t, "1.3.0", got.NextVersion)
+		require.Equal(t, compareStatusAhead, got.CompareStatus)
+		require.Len(t, got.Commits, 2)
+		require.Equal(t, "#12 is a minor change. If it is merged, the next release will be 1.3.0 (previous release 1.2.0).", got.summary())
+	})
+
+	t.Run("config labels", func(t *testing.T) {
+		cfg := config{Labels: map[string]string{"Impact: Breaking": "major"}}
+		require.NoError(t, cfg.validate())
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("impact: breaking"),
+			repo:   "willabides/semver-next",
+			number: 12,
+			rules:  cfg.pullRules(),
+		})
+		require.NoError(t, err)
+		require.Empty(t, got.Problems)
+		require.Equal(t, "2.0.0", got.NextVersion)
+	})
+
+	t.Run("no labels", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("documentation"),
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"pull request #12 has no semver labels. Add one of semver:major, semver:minor, semver:patch or semver:none.",
+		}, got.Problems)
+		require.Equal(t, "1.2.1", got.NextVersion)
+	})
+
+	t.Run("no labels or conventional title", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("documentation"),
+			repo:   "willabides/semver-next",
+			number: 12,
+			rules:  &pullRules{titleMode: titleModeLabelFirst},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"pull request #12 has no semver labels or conventional commit title. Add one of semver:major, " +
+				`semver:minor, semver:patch or semver:none, or use a title like "fix: ..." or "feat: ...".`,
+		}, got.Problems)
+	})
+
+	t.Run("conflicting labels", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("bug", "breaking", "fix"),
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"pull request #12 has labels for different change levels: breaking (major), bug (patch), fix (patch). Remove all but one.",
+		}, got.Problems)
+	})
+
+	t.Run("override label", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("semver:force-major"),
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.NoError(t, err)
+		require.Empty(t, got.Problems)
+		require.Equal(t, "2.0.0", got.NextVersion)
+		require.Equal(t, []ResultOverride{
+			{Label: "semver:force-major", Pull: 12, Commit: headSha, ChangeLevel: changeLevelMajor, kind: overrideForce, level: changeLevelMajor},
+		}, got.Overrides)
+	})
+	t.Run("no change", func(t *testing.T) {
+		got, err := predictPull(ctx, pullOptions{
+			gh:     stub("semver:none"),
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "#12 doesn't change the version. If it is merged, the next release will be 1.2.1 (previous release 1.2.0).", got.summary())
+	})
+
+	t.Run("base branch diverged from the previous release", func(t *testing.T) {
+		gh := stub("bug")
+		gh.compareStatus = compareStatusDiverged
+		_, err := predictPull(ctx, pullOptions{
+			gh:     gh,
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.EqualError(t, err, "main has diverged from v1.2.0; the head ref must descend from the base ref")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		gh := (&wrapperStub{}).withPullRequest(t, pullRequest{Number: 12, Labels: []string{"bug"}, State: "closed"})
+		_, err := predictPull(ctx, pullOptions{
+			gh:     gh,
+			repo:   "willabides/semver-next",
+			number: 12,
+		})
+		require.EqualError(t, err, "pull request #12 is closed; only open pull requests can be checked")
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const checkRunName = "semver-next"
+
+type checkRunCmd struct {
+	Repo        string `kong:"arg,required,help=${repo_help}"`
+	Number      int    `kong:"arg,required,help=${pull_number_help}"`
+	PrevRef     string `kong:"short=p,help=${pull_prev_ref_help}"`
+	PrevVersion string `kong:"prev-version,short=v,help=${prev_version_help}"`
+}
+
+func (c *checkRunCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	res, err := predictPull(ctx, pullOptions{
+		gh:                  gh,
+		repo:                c.Repo,
+		number:              c.Number,
+		base:                c.PrevRef,
+		prevVersion:         c.PrevVersion,
+		rules:               cfg.pullRules(),
+		maintenanceBranches: cfg.maintenanceBranches,
+	})
+	if err != nil {
+		return err
+	}
+	owner, repo, err := splitRepo(c.Repo)
+	if err != nil {
+		return err
+	}
+	return gh.CreateCheckRun(ctx, owner, repo, pullCheckRun(res))
+}
+
+// pullCheckRun builds the check run for a pull request's head commit. It fails when the pull request's labels are
+// missing or conflicting.
+func pullCheckRun(res *PullResult) *checkRun {
+	run := checkRun{
+		Name:       checkRunName,
+		HeadSha:    res.HeadSha,
+		Conclusion: "success",
+		Title:      fmt.Sprintf("%s change, next version %s", res.Pull.ChangeLevel, res.NextVersion),
+	}
+	var sb strings.Builder
+	if len(res.Problems) > 0 {
+		run.Conclusion = "failure"
+		run.Title = "Invalid semver labels"
+		for _, p := range res.Problems {
+			fmt.Fprintf(&sb, "- %s\n", p)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(pullSummary(res))
+	run.Summary = sb.String()
+	return &run
+}
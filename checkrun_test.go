@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pullCheckRun(t *testing.T) {
+	res := PullResult{
+		Pull:    ResultPull{Number: 12, Labels: []string{"enhancement"}, ChangeLevel: changeLevelMinor},
+		HeadSha: "9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		Result: Result{
+			PreviousVersion: "1.2.0",
+			NextVersion:     "1.3.0",
+			ChangeLevel:     changeLevelMinor,
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		got := pullCheckRun(&res)
+		require.Equal(t, &checkRun{
+			Name:       "semver-next",
+			HeadSha:    res.HeadSha,
+			Conclusion: "success",
+			Title:      "minor change, next version 1.3.0",
+			Summary:    pullSummary(&res),
+		}, got)
+	})
+
+	t.Run("missing labels", func(t *testing.T) {
+		res := res
+		res.Pull = ResultPull{Number: 12, Labels: []string{}}
+		res.Problems = []string{"no labels"}
+		got := pullCheckRun(&res)
+		require.Equal(t, "failure", got.Conclusion)
+		require.Equal(t, "Invalid semver labels", got.Title)
+		require.Equal(t, "- no labels\n\n"+pullSummary(&res), got.Summary)
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// pullCommentMarker identifies the comment semver-next maintains on a pull request.
+const pullCommentMarker = "<!-- semver-next -->"
+
+type commentPRCmd struct {
+	Repo        string `kong:"arg,required,help=${repo_help}"`
+	Number      int    `kong:"arg,required,help=${pull_number_help}"`
+	PrevRef     string `kong:"short=p,help=${pull_prev_ref_help}"`
+	PrevVersion string `kong:"prev-version,short=v,help=${prev_version_help}"`
+}
+
+func (c *commentPRCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	res, err := predictPull(ctx, pullOptions{
+		gh:                  gh,
+		repo:                c.Repo,
+		number:              c.Number,
+		base:                c.PrevRef,
+		prevVersion:         c.PrevVersion,
+		rules:               cfg.pullRules(),
+		maintenanceBranches: cfg.maintenanceBranches,
+	})
+	if err != nil {
+		return err
+	}
+	owner, repo, err := splitRepo(c.Repo)
+	if err != nil {
+		return err
+	}
+	return upsertPullComment(ctx, gh, owner, repo, c.Number, pullComment(res))
+}
+
+// pullComment renders the markdown comment for a pull request.
+func pullComment(res *PullResult) string {
+	var sb strings.Builder
+	sb.WriteString(pullCommentMarker + "\n")
+	sb.WriteString("### semver-next\n\n")
+	for _, p := range res.Problems {
+		fmt.Fprintf(&sb, "> [!WARNING]\n> %s\n\n", p)
+	}
+	sb.WriteString(pullSummary(res))
+	return sb.String()
+}
+
+// pullSummary renders a markdown table of the versions predicted for a pull request.
+func pullSummary(res *PullResult) string {
+	var sb strings.Builder
+	labels := "none"
+	if len(res.Pull.Labels) > 0 {
+		labels = "`" + strings.Join(res.Pull.Labels, "`, `") + "`"
+	}
+	sb.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Previous version | %s |\n", res.PreviousVersion)
+	fmt.Fprintf(&sb, "| Next version if merged | %s |\n", res.NextVersion)
+	fmt.Fprintf(&sb, "| This pull request | %s (labels: %s) |\n", res.Pull.ChangeLevel, labels)
+	if res.ChangeLevel > res.Pull.ChangeLevel {
+		fmt.Fprintf(&sb, "\nChanges merged since %s already require a %s release.\n", res.PreviousVersion, res.ChangeLevel)
+	}
+	return sb.String()
+}
+
+// upsertPullComment creates or updates the pull request comment containing pullCommentMarker.
+func upsertPullComment(ctx context.Context, gh wrapper, owner, repo string, number int, body string) error {
+	comments, err := gh.ListIssueComments(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if !strings.Contains(c.Body, pullCommentMarker) {
+			continue
+		}
+		if c.Body == body {
+			return nil
+		}
+		return gh.EditIssueComment(ctx, owner, repo, c.ID, body)
+	}
+	return gh.CreateIssueComment(ctx, owner, repo, number, body)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pullComment(t *testing.T) {
+	got := pullComment(&PullResult{
+		Pull: ResultPull{Number: 12, Labels: []string{"bug"}, ChangeLevel: changeLevelPatch},
+		Result: Result{
+			PreviousVersion: "1.2.0",
+			NextVersion:     "1.3.0",
+			ChangeLevel:     changeLevelMinor,
+		},
+	})
+	want := `<!-- semver-next -->
+### semver-next
+
+| | |
+|---|---|
+| Previous version | 1.2.0 |
+| Next version if merged | 1.3.0 |
+| This pull request | patch (labels: ` + "`bug`" + `) |
+
+Changes merged since 1.2.0 already require a minor release.
+`
+	require.Equal(t, want, got)
+}
+
+func Test_upsertPullComment(t *testing.T) {
+	ctx := context.Background()
+	body := pullCommentMarker + "\nnew"
+
+	t.Run("create", func(t *testing.T) {
+		var created string
+		gh := &wrapperStub{
+			listIssueComments: func(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+				assert.Equal(t, 12, number)
+				return []issueComment{{ID: 1, Body: "unrelated"}}, nil
+			},
+			createIssueComment: func(ctx context.Context, owner, repo string, number int, b string) error {
+				created = b
+				return nil
+			},
+		}
+		require.NoError(t, upsertPullComment(ctx, gh, "willabides", "semver-next", 12, body))
+		require.Equal(t, body, created)
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		var editedID int64
+		gh := &wrapperStub{
+			listIssueComments: func(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+				return []issueComment{{ID: 1, Body: "unrelated"}, {ID: 2, Body: pullCommentMarker + "\nold"}}, nil
+			},
+			editIssueComment: func(ctx context.Context, owner, repo string, id int64, b string) error {
+				editedID = id
+				assert.Equal(t, body, b)
+				return nil
+			},
+		}
+		require.NoError(t, upsertPullComment(ctx, gh, "willabides", "semver-next", 12, body))
+		require.Equal(t, int64(2), editedID)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		gh := &wrapperStub{
+			listIssueComments: func(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+				return []issueComment{{ID: 2, Body: body}}, nil
+			},
+		}
+		require.NoError(t, upsertPullComment(ctx, gh, "willabides", "semver-next", 12, body))
+	})
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config is the semver-next configuration file.
+type config struct {
+	// Labels maps additional label names to change levels. They take precedence over the default labels.
+	Labels map[string]string `yaml:"labels"`
+
+	// LabelPatterns match labels with regular expressions or globs. They are evaluated in order after exact matches
+	// from Labels and the default labels.
+	LabelPatterns []labelPatternConfig `yaml:"label_patterns"`
+
+	// TitleMode enables determining change levels from pull request titles in conventional commit format. It is one
+	// of "label-first", "title-first" or "max". Titles are ignored when empty.
+	TitleMode string `yaml:"title_mode"`
+
+	// Body configures determining change levels and release notes from pull request bodies.
+	Body *bodyConfig `yaml:"body"`
+
+	Packages []packageConfig `yaml:"packages"`
+
+	// MaintenanceBranches are regular expressions matching maintenance branch names. Each has a "major" named group
+	// and an optional "minor" named group. Defaults to matching "release/<major>.<minor>.x" and "release/<major>.x".
+	MaintenanceBranches []string `yaml:"maintenance_branches"`
+
+	// labelLevels is Labels with lowercase keys and parsed levels.
+	labelLevels map[string]changeLevel
+
+	// labelPatterns is LabelPatterns compiled.
+	labelPatterns []*labelPattern
+
+	// bodyRules is Body with parsed levels.
+	bodyRules *bodyRules
+
+	// maintenanceBranches is MaintenanceBranches compiled. It is nil when MaintenanceBranches is empty.
+	maintenanceBranches []*regexp.Regexp
+}
+
+// labelPatternConfig maps labels matching Regex or Glob to Level. Exactly one of Regex or Glob is required.
+type labelPatternConfig struct {
+	Regex string `yaml:"regex"`
+	Glob  string `yaml:"glob"`
+	Level string `yaml:"level"`
+}
+
+// bodyConfig configures parsing pull request bodies.
+type bodyConfig struct {
+	// Checkboxes maps the text of markdown checkboxes to change levels. A checked box matches when its text starts
+	// with the key, ignoring case.
+	Checkboxes map[string]string `yaml:"checkboxes"`
+
+	// BreakingChange makes a line starting with "BREAKING CHANGE:" a major change.
+	BreakingChange bool `yaml:"breaking_change"`
+
+	// ReleaseNoteBlock is the info string of fenced code blocks containing release notes. e.g. "release-note"
+	ReleaseNoteBlock string `yaml:"release_note_block"`
+}
+
+// packageConfig declares a separately versioned package in a repository.
+type packageConfig struct {
+	// Name identifies the package in output. Required.
+	Name string `yaml:"name"`
+
+	// Path is the package's directory relative to the repository root. Only commits changing files in Path count
+	// toward the package's version. Defaults to the repository root.
+	Path string `yaml:"path"`
+
+	// TagPrefix is prepended to the version in the package's release tags. Defaults to "<path>/" or "" for the
+	// repository root.
+	TagPrefix string `yaml:"tag_prefix"`
+
+	// LabelNamespace is the namespace for package-specific labels. A pull request labeled "semver:<namespace>:minor"
+	// is a minor change to the package. Defaults to Name.
+	LabelNamespace string `yaml:"label_namespace"`
+}
+
+func (p *packageConfig) tagPrefix() string {
+	if p.TagPrefix != "" || p.packagePath() == "" {
+		return p.TagPrefix
+	}
+	return p.packagePath() + "/"
+}
+
+// packagePath returns the package's directory in the form used by commit file names. The repository root is "".
+func (p *packageConfig) packagePath() string {
+	path := strings.Trim(p.Path, "/")
+	if path == "." {
+		return ""
+	}
+	return path
+}
+
+func (p *packageConfig) labelNamespace() string {
+	if p.LabelNamespace != "" {
+		return p.LabelNamespace
+	}
+	return p.Name
+}
+
+func loadConfig(filename string) (*config, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	err = yaml.Unmarshal(b, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", filename, err)
+	}
+	err = cfg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", filename, err)
+	}
+	return &cfg, nil
+}
+
+func (c *config) validate() error {
+	c.labelLevels = make(map[string]changeLevel, len(c.Labels))
+	for label, levelName := range c.Labels {
+		level, err := parseChangeLevel(levelName)
+		if err != nil {
+			return fmt.Errorf("label %q: %v", label, err)
+		}
+		c.labelLevels[strings.ToLower(label)] = level
+	}
+	c.labelPatterns = make([]*labelPattern, len(c.LabelPatterns))
+	for i := range c.LabelPatterns {
+		var err error
+		c.labelPatterns[i], err = newLabelPattern(&c.LabelPatterns[i])
+		if err != nil {
+			return fmt.Errorf("label_patterns[%d]: %v", i, err)
+		}
+	}
+	switch c.TitleMode {
+	case "", titleModeLabelFirst, titleModeTitleFirst, titleModeMax:
+	default:
+		return fmt.Errorf("invalid title_mode %q", c.TitleMode)
+	}
+	if c.Body != nil {
+		c.bodyRules = &bodyRules{
+			checkboxes:       make(map[string]changeLevel, len(c.Body.Checkboxes)),
+			breakingChange:   c.Body.BreakingChange,
+			releaseNoteBlock: c.Body.ReleaseNoteBlock,
+		}
+		for text, levelName := range c.Body.Checkboxes {
+			level, err := parseChangeLevel(levelName)
+			if err != nil {
+				return fmt.Errorf("checkbox %q: %v", text, err)
+			}
+			c.bodyRules.checkboxes[strings.ToLower(text)] = level
+		}
+	}
+	c.maintenanceBranches = nil
+	for i, pattern := range c.MaintenanceBranches {
+		re, err := compileMaintenanceBranch(pattern)
+		if err != nil {
+			return fmt.Errorf("maintenance_branches[%d]: %v", i, err)
+		}
+		c.maintenanceBranches = append(c.maintenanceBranches, re)
+	}
+	names := map[string]bool{}
+	for _, p := range c.Packages {
+		if p.Name == "" {
+			return fmt.Errorf("package name is required")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate package name %q", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// labelLevel recognizes the labels from the config file, the default labels and then the label patterns from the
+// config file.
+func (c *config) labelLevel(label string) (changeLevel, bool) {
+	level, ok := c.labelLevels[label]
+	if ok {
+		return level, true
+	}
+	level, ok = defaultLabelLevel(label)
+	if ok {
+		return level, true
+	}
+	for _, p := range c.labelPatterns {
+		if p.match(label) {
+			return p.level, true
+		}
+	}
+	return changeLevelNoChange, false
+}
+
+// displayLabels returns the labels recognized with this config. Patterns are keyed by "regex:<pattern>" or
+// "glob:<pattern>".
+func (c *config) displayLabels() map[string]changeLevel {
+	result := make(map[string]changeLevel, len(labelLevels)+len(c.labelLevels)+len(c.labelPatterns))
+	for label, level := range labelLevels {
+		result[label] = level
+	}
+	for label, level := range c.labelLevels {
+		result[label] = level
+	}
+	for _, p := range c.labelPatterns {
+		result[p.String()] = p.level
+	}
+	return result
+}
+
+func (c *config) pullRules() *pullRules {
+	return &pullRules{
+		labelLevel: c.labelLevel,
+		titleMode:  c.TitleMode,
+		body:       c.bodyRules,
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		filename := filepath.Join(t.TempDir(), "semver-next.yaml")
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+		return filename
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		cfg, err := loadConfig(writeConfig(t, `
+labels:
+  Impact/Feature: minor
+  bug: major
+label_patterns:
+  - regex: ^impact/(breaking|major)$
+    level: major
+  - glob: team-*/bug
+    level: patch
+  - glob: "*"
+    level: none
+`))
+		require.NoError(t, err)
+		for label, want := range map[string]changeLevel{
+			"impact/feature":  changeLevelMinor,
+			"bug":             changeLevelMajor,
+			"enhancement":     changeLevelMinor,
+			"impact/breaking": changeLevelMajor,
+			"team-x/bug":      changeLevelPatch,
+			"anything":        changeLevelNoChange,
+		} {
+			got, ok := cfg.labelLevel(label)
+			require.True(t, ok, label)
+			require.Equal(t, want, got, label)
+		}
+		_, ok := cfg.labelLevel("team-x/other")
+		require.False(t, ok)
+
+		display := cfg.displayLabels()
+		require.Equal(t, changeLevelMajor, display["regex:^impact/(breaking|major)$"])
+		require.Equal(t, changeLevelPatch, display["glob:team-*/bug"])
+		require.Equal(t, changeLevelMinor, display["impact/feature"])
+		require.Equal(t, changeLevelMajor, display["semver:major"])
+	})
+
+	for _, td := range []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "invalid label level",
+			content: "labels:\n  foo: huge\n",
+			wantErr: `label "foo": invalid change level: huge`,
+		},
+		{
+			name:    "regex and glob",
+			content: "label_patterns:\n  - regex: foo\n    glob: foo\n    level: major\n",
+			wantErr: "label_patterns[0]: exactly one of regex or glob is required",
+		},
+		{
+			name:    "invalid regex",
+			content: "label_patterns:\n  - regex: \"(\"\n    level: major\n",
+			wantErr: "label_patterns[0]: error parsing regexp: missing closing ): `(?i)(`",
+		},
+		{
+			name:    "invalid glob",
+			content: "label_patterns:\n  - glob: \"[\"\n    level: major\n",
+			wantErr: `label_patterns[0]: invalid glob "[": syntax error in pattern`,
+		},
+		{
+			name:    "invalid title_mode",
+			content: "title_mode: sometimes\n",
+			wantErr: `invalid title_mode "sometimes"`,
+		},
+		{
+			name:    "duplicate package",
+			content: "packages:\n  - name: foo\n  - name: foo\n",
+			wantErr: `duplicate package name "foo"`,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			filename := writeConfig(t, td.content)
+			_, err := loadConfig(filename)
+			require.EqualError(t, err, "invalid config file "+filename+": "+td.wantErr)
+		})
+	}
+}
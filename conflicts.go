@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ways to handle a pull request with labels for different change levels.
+const (
+	conflictingLabelsMax   = "max"
+	conflictingLabelsWarn  = "warn"
+	conflictingLabelsError = "error"
+)
+
+// labelConflict describes pull's labels when they are for different change levels. It returns "" when they agree.
+func labelConflict(pull ResultPull, rules *pullRules) string {
+	levels := map[changeLevel]bool{}
+	described := make([]string, len(pull.Labels))
+	for i, l := range pull.Labels {
+		level, _ := rules.levelForLabel(l)
+		levels[level] = true
+		described[i] = fmt.Sprintf("%s (%s)", l, level)
+	}
+	if len(levels) < 2 {
+		return ""
+	}
+	sort.Strings(described)
+	return fmt.Sprintf(
+		"pull request #%d has labels for different change levels: %s",
+		pull.Number, strings.Join(described, ", "),
+	)
+}
+
+// checkConflictingLabels finds pull requests in result.Commits with labels for different change levels. Conflicts
+// are added to result.Warnings when mode is conflictingLabelsWarn and returned as an error when mode is
+// conflictingLabelsError. The highest level wins either way.
+func checkConflictingLabels(mode string, rules *pullRules, result *Result) error {
+	if mode == "" || mode == conflictingLabelsMax {
+		return nil
+	}
+	var conflicts []string
+	seen := map[int]bool{}
+	for _, c := range result.Commits {
+		for _, p := range c.Pulls {
+			if seen[p.Number] {
+				continue
+			}
+			seen[p.Number] = true
+			if msg := labelConflict(p, rules); msg != "" {
+				conflicts = append(conflicts, msg)
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if mode == conflictingLabelsError {
+		return fmt.Errorf("pull requests with conflicting semver labels:\n%s", strings.Join(conflicts, "\n"))
+	}
+	result.Warnings = append(result.Warnings, conflicts...)
+	return nil
+}
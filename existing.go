@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkExistingVersion looks for a tag with result.NextVersion among tags starting with prefix. When one exists it
+// returns an error, or when autoSkip is true it keeps bumping at result.ChangeLevel until reaching a version without
+// a tag. Skipped tags are recorded in result.ExistingTags and result.Warnings.
+func checkExistingVersion(tags []string, prefix string, scheme versionScheme, autoSkip bool, result *Result) error {
+	if result.ChangeLevel == changeLevelNoChange {
+		return nil
+	}
+	var existing []schemeVersion
+	var existingTags []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		v, err := scheme.parse(strings.TrimPrefix(tag, prefix))
+		if err != nil {
+			continue
+		}
+		existing = append(existing, v)
+		existingTags = append(existingTags, tag)
+	}
+	findTag := func(v schemeVersion) string {
+		for i := range existing {
+			if existing[i].compare(v) == 0 {
+				return existingTags[i]
+			}
+		}
+		return ""
+	}
+
+	next, err := scheme.parse(result.NextVersion)
+	if err != nil {
+		return err
+	}
+	// every bump is a new version, so there can't be more collisions than tags
+	for i := 0; i <= len(existing); i++ {
+		tag := findTag(next)
+		if tag == "" {
+			break
+		}
+		if !autoSkip {
+			return fmt.Errorf("next version %s already exists as tag %s; use --auto-skip to skip to the next free version", next, tag)
+		}
+		result.ExistingTags = append(result.ExistingTags, tag)
+		skipped := next
+		next = next.bump(result.ChangeLevel)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("version %s already exists as tag %s; skipping to %s", skipped, tag, next))
+	}
+	result.NextVersion = next.String()
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkExistingVersion(t *testing.T) {
+	tags := []string{"v1.2.0", "v1.3.0", "v1.4.0", "api/v1.5.0"}
+
+	t.Run("free", func(t *testing.T) {
+		result := &Result{NextVersion: "1.2.1", ChangeLevel: changeLevelPatch}
+		require.NoError(t, checkExistingVersion(tags, "", semverScheme{}, false, result))
+		require.Equal(t, "1.2.1", result.NextVersion)
+		require.Empty(t, result.ExistingTags)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		result := &Result{NextVersion: "1.3.0", ChangeLevel: changeLevelMinor}
+		err := checkExistingVersion(tags, "", semverScheme{}, false, result)
+		require.EqualError(t, err, "next version 1.3.0 already exists as tag v1.3.0; use --auto-skip to skip to the next free version")
+	})
+
+	t.Run("auto skip", func(t *testing.T) {
+		result := &Result{NextVersion: "1.3.0", ChangeLevel: changeLevelMinor}
+		require.NoError(t, checkExistingVersion(tags, "", semverScheme{}, true, result))
+		require.Equal(t, "1.5.0", result.NextVersion)
+		require.Equal(t, []string{"v1.3.0", "v1.4.0"}, result.ExistingTags)
+		require.Equal(t, []string{
+			"version 1.3.0 already exists as tag v1.3.0; skipping to 1.4.0",
+			"version 1.4.0 already exists as tag v1.4.0; skipping to 1.5.0",
+		}, result.Warnings)
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		result := &Result{NextVersion: "1.5.0", ChangeLevel: changeLevelMinor}
+		require.NoError(t, checkExistingVersion(tags, "api/", semverScheme{}, true, result))
+		require.Equal(t, "1.6.0", result.NextVersion)
+		require.Equal(t, []string{"api/v1.5.0"}, result.ExistingTags)
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		result := &Result{NextVersion: "1.2.0", ChangeLevel: changeLevelNoChange}
+		require.NoError(t, checkExistingVersion(tags, "", semverScheme{}, false, result))
+		require.Equal(t, "1.2.0", result.NextVersion)
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// explainResult writes a human-readable trace of how res was determined: each commit and its pull requests with the
+// labels that matched, the pull request that set the change level, and any API changes, overrides or bump limits
+// that changed it.
+func explainResult(w io.Writer, res *Result, rules *pullRules) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "previous version: %s\n", res.PreviousVersion)
+	if len(res.Commits) == 0 {
+		b.WriteString("no commits since the previous version\n")
+	}
+	for _, c := range res.Commits {
+		if c.CherryPickedFrom != "" {
+			fmt.Fprintf(&b, "commit %s: cherry-picked from %s\n", shortSha(c.Sha), shortSha(c.CherryPickedFrom))
+		}
+		if len(c.Pulls) == 0 {
+			fmt.Fprintf(&b, "commit %s: %s (no pull requests)\n", shortSha(c.Sha), c.ChangeLevel)
+			continue
+		}
+		fmt.Fprintf(&b, "commit %s: %s\n", shortSha(c.Sha), c.ChangeLevel)
+		for _, p := range c.Pulls {
+			explainPull(&b, p, rules)
+		}
+	}
+
+	level := commitsChangeLevel(res.Commits)
+	if pull, sha, ok := levelSetter(res.Commits, level); ok {
+		fmt.Fprintf(&b, "%s set by #%d (commit %s)\n", level, pull, shortSha(sha))
+	} else {
+		fmt.Fprintf(&b, "%s from commits\n", level)
+	}
+	if apiLevel := apiChangeLevel(res.APIChanges); apiLevel > level {
+		fmt.Fprintf(&b, "API changes raised the level from %s to %s\n", level, apiLevel)
+		level = apiLevel
+	}
+	for _, o := range res.Overrides {
+		if o.ChangeLevel != level {
+			fmt.Fprintf(&b, "%s on #%d changed the level from %s to %s\n", o.Label, o.Pull, level, o.ChangeLevel)
+		} else {
+			fmt.Fprintf(&b, "%s on #%d left the level at %s\n", o.Label, o.Pull, level)
+		}
+		level = o.ChangeLevel
+	}
+	switch res.ClampedBy {
+	case clampedByMinBump:
+		fmt.Fprintf(&b, "--min-bump raised the level from %s to %s\n", res.ComputedChangeLevel, res.AppliedChangeLevel)
+	case clampedByMaxBump:
+		fmt.Fprintf(&b, "--max-bump lowered the level from %s to %s\n", res.ComputedChangeLevel, res.AppliedChangeLevel)
+	}
+	for _, warning := range res.Warnings {
+		fmt.Fprintf(&b, "warning: %s\n", warning)
+	}
+	fmt.Fprintf(&b, "next version: %s\n", res.NextVersion)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func explainPull(b *strings.Builder, p ResultPull, rules *pullRules) {
+	if p.Title != "" {
+		fmt.Fprintf(b, "  #%d %q: %s\n", p.Number, p.Title, p.ChangeLevel)
+	} else {
+		fmt.Fprintf(b, "  #%d: %s\n", p.Number, p.ChangeLevel)
+	}
+	if len(p.Labels) == 0 {
+		b.WriteString("    labels: none matched\n")
+	} else {
+		described := make([]string, len(p.Labels))
+		for i, l := range p.Labels {
+			level, _ := rules.levelForLabel(l)
+			described[i] = fmt.Sprintf("%s (%s)", l, level)
+		}
+		fmt.Fprintf(b, "    labels: %s\n", strings.Join(described, ", "))
+	}
+	if p.TitleChangeLevel != nil {
+		fmt.Fprintf(b, "    title: %s\n", *p.TitleChangeLevel)
+	}
+	if p.BodyChangeLevel != nil {
+		fmt.Fprintf(b, "    body: %s\n", *p.BodyChangeLevel)
+	}
+	if p.BackportOf != 0 {
+		fmt.Fprintf(b, "    backport of #%d\n", p.BackportOf)
+	}
+	if len(p.OverrideLabels) > 0 {
+		fmt.Fprintf(b, "    overrides: %s\n", strings.Join(p.OverrideLabels, ", "))
+	}
+}
+
+// levelSetter returns the first pull request in commits with the given change level and the sha of its commit.
+func levelSetter(commits []ResultCommit, level changeLevel) (pull int, sha string, ok bool) {
+	for _, c := range commits {
+		for _, p := range c.Pulls {
+			if p.ChangeLevel == level {
+				return p.Number, c.Sha, true
+			}
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_explainResult(t *testing.T) {
+	titleLevel := changeLevelPatch
+	res := &Result{
+		NextVersion:         "1.3.0",
+		PreviousVersion:     "1.2.0",
+		ChangeLevel:         changeLevelMinor,
+		ComputedChangeLevel: changeLevelMajor,
+		AppliedChangeLevel:  changeLevelMinor,
+		ClampedBy:           clampedByMaxBump,
+		Commits: []ResultCommit{
+			{
+				Sha:         "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				ChangeLevel: changeLevelMajor,
+				Pulls: []ResultPull{
+					{Number: 1, Title: "fix: a bug", Labels: []string{"breaking"}, ChangeLevel: changeLevelMajor, TitleChangeLevel: &titleLevel},
+					{Number: 2, Labels: []string{}},
+				},
+			},
+			{Sha: "2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+		},
+		Warnings: []string{"something"},
+	}
+	var buf strings.Builder
+	require.NoError(t, explainResult(&buf, res, nil))
+	require.Equal(t, `previous version: 1.2.0
+commit 1aaaaaa: major
+  #1 "fix: a bug": major
+    labels: breaking (major)
+    title: patch
+  #2: no change
+    labels: none matched
+commit 2aaaaaa: no change (no pull requests)
+major set by #1 (commit 1aaaaaa)
+--max-bump lowered the level from major to minor
+warning: something
+next version: 1.3.0
+`, buf.String())
+
+	t.Run("overrides", func(t *testing.T) {
+		res := &Result{
+			NextVersion:     "2.0.0",
+			PreviousVersion: "1.2.0",
+			ChangeLevel:     changeLevelMajor,
+			Commits: []ResultCommit{{
+				Sha:         "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				ChangeLevel: changeLevelPatch,
+				Pulls:       []ResultPull{{Number: 1, Labels: []string{"bug"}, ChangeLevel: changeLevelPatch, OverrideLabels: []string{"semver:force-major"}}},
+			}},
+			Overrides: []ResultOverride{{Label: "semver:force-major", Pull: 1, ChangeLevel: changeLevelMajor}},
+		}
+		var buf strings.Builder
+		require.NoError(t, explainResult(&buf, res, nil))
+		require.Contains(t, buf.String(), "    overrides: semver:force-major\n")
+		require.Contains(t, buf.String(), "patch set by #1 (commit 1aaaaaa)\nsemver:force-major on #1 changed the level from patch to major\nnext version: 2.0.0\n")
+	})
+}
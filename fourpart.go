@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fourPartScheme is versions with four numeric components like "1.2.3.4". Major, minor and patch changes increment
+// the first, second and third components and reset the components after them to 0.
+type fourPartScheme struct{}
+
+func (fourPartScheme) parse(v string) (schemeVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%q is not a four-part version", v)
+	}
+	var result fourPartVersion
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a four-part version", v)
+		}
+		result[i] = int(n)
+	}
+	return result, nil
+}
+
+type fourPartVersion [4]int
+
+func (v fourPartVersion) bump(level changeLevel) schemeVersion {
+	var idx int
+	switch level {
+	case changeLevelMajor:
+		idx = 0
+	case changeLevelMinor:
+		idx = 1
+	case changeLevelPatch:
+		idx = 2
+	default:
+		return v
+	}
+	next := v
+	next[idx]++
+	for i := idx + 1; i < len(next); i++ {
+		next[i] = 0
+	}
+	return next
+}
+
+func (v fourPartVersion) compare(other schemeVersion) int {
+	o := other.(fourPartVersion)
+	for i := range v {
+		if c := compareInts(v[i], o[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (v fourPartVersion) prerelease() bool {
+	return false
+}
+
+func (v fourPartVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
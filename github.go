@@ -2,15 +2,96 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/google/go-github/v52/github"
 )
 
 type wrapper interface {
 	ListPullRequestsWithCommit(ctx context.Context, owner, repo, sha string) ([]ResultPull, error)
-	CompareCommits(ctx context.Context, owner, repo, base, head string) ([]string, error)
+	CompareCommits(ctx context.Context, owner, repo, base, head string) (*comparison, error)
+	ListTags(ctx context.Context, owner, repo string) ([]string, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error)
+	GetCommitSha(ctx context.Context, owner, repo, ref string) (string, error)
+	GetCommitDate(ctx context.Context, owner, repo, ref string) (time.Time, error)
+	ListCommitFiles(ctx context.Context, owner, repo, sha string) ([]string, error)
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*pullRequest, error)
+	ListIssueComments(ctx context.Context, owner, repo string, number int) ([]issueComment, error)
+	CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error
+	EditIssueComment(ctx context.Context, owner, repo string, id int64, body string) error
+	CreateCheckRun(ctx context.Context, owner, repo string, run *checkRun) error
+	ListLabels(ctx context.Context, owner, repo string) ([]repoLabel, error)
+	CreateLabel(ctx context.Context, owner, repo string, label repoLabel) error
+	EditLabel(ctx context.Context, owner, repo, name string, label repoLabel) error
+	DeleteLabel(ctx context.Context, owner, repo, name string) error
 }
 
+// repoCommit is a commit returned by CompareCommits.
+type repoCommit struct {
+	Sha     string
+	Message string
+}
+
+// comparison is the result of CompareCommits.
+type comparison struct {
+	// Status is how head relates to base. One of "ahead", "behind", "diverged" or "identical".
+	Status  string
+	Commits []repoCommit
+}
+
+// commitShas returns the shas of commits.
+func commitShas(commits []repoCommit) []string {
+	shas := make([]string, len(commits))
+	for i, c := range commits {
+		shas[i] = c.Sha
+	}
+	return shas
+}
+
+// repoLabel is a label defined in a repository.
+type repoLabel struct {
+	Name        string
+	Color       string
+	Description string
+}
+
+// checkRun is a completed check run.
+type checkRun struct {
+	Name       string
+	HeadSha    string
+	Conclusion string
+	Title      string
+	Summary    string
+}
+
+// issueComment is a comment on an issue or pull request.
+type issueComment struct {
+	ID   int64
+	Body string
+}
+
+// pullRequest is a pull request fetched by number.
+type pullRequest struct {
+	Number  int
+	Title   string
+	Body    string
+	Labels  []string
+	State   string
+	BaseRef string
+	BaseSha string
+	HeadSha string
+}
+
+func (p *pullRequest) resultPull() ResultPull {
+	return ResultPull{Number: p.Number, Title: p.Title, Body: p.Body, Labels: p.Labels}
+}
+
+// errCommitNotFound is returned by ListPullRequestsWithCommit when the commit isn't in the repository.
+var errCommitNotFound = errors.New("commit not found")
+
 type ghWrapper struct {
 	client *github.Client
 }
@@ -22,6 +103,11 @@ func (g *ghWrapper) ListPullRequestsWithCommit(ctx context.Context, owner, repo,
 	}
 	for {
 		apiPulls, resp, err := g.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, sha, opts)
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && (errResp.Response.StatusCode == http.StatusNotFound ||
+			errResp.Response.StatusCode == http.StatusUnprocessableEntity) {
+			return nil, fmt.Errorf("listing pull requests for %s: %w", sha, errCommitNotFound)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -31,6 +117,8 @@ func (g *ghWrapper) ListPullRequestsWithCommit(ctx context.Context, owner, repo,
 			}
 			resultPull := ResultPull{
 				Number: apiPull.GetNumber(),
+				Title:  apiPull.GetTitle(),
+				Body:   apiPull.GetBody(),
 				Labels: make([]string, len(apiPull.Labels)),
 			}
 			for i, label := range apiPull.Labels {
@@ -46,16 +134,46 @@ func (g *ghWrapper) ListPullRequestsWithCommit(ctx context.Context, owner, repo,
 	return result, nil
 }
 
-func (g *ghWrapper) CompareCommits(ctx context.Context, owner, repo, base, head string) ([]string, error) {
-	var result []string
+func (g *ghWrapper) CompareCommits(ctx context.Context, owner, repo, base, head string) (*comparison, error) {
+	var result comparison
+	var totalCommits int
 	opts := &github.ListOptions{PerPage: 100}
 	for {
 		comp, resp, err := g.client.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
 		if err != nil {
 			return nil, err
 		}
+		result.Status = comp.GetStatus()
+		totalCommits = comp.GetTotalCommits()
 		for _, commit := range comp.Commits {
-			result = append(result, commit.GetSHA())
+			result.Commits = append(result.Commits, repoCommit{
+				Sha:     commit.GetSHA(),
+				Message: commit.GetCommit().GetMessage(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	// Without pagination the compare API stops listing commits at 250. Paginated responses list every commit, so a
+	// shortfall means commits would be silently missed.
+	if totalCommits > len(result.Commits) {
+		return nil, fmt.Errorf("comparing %s...%s listed %d of %d commits", base, head, len(result.Commits), totalCommits)
+	}
+	return &result, nil
+}
+
+func (g *ghWrapper) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	var result []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, resp, err := g.client.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			result = append(result, tag.GetName())
 		}
 		if resp.NextPage == 0 {
 			break
@@ -64,3 +182,168 @@ func (g *ghWrapper) CompareCommits(ctx context.Context, owner, repo, base, head
 	}
 	return result, nil
 }
+
+func (g *ghWrapper) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	file, _, _, err := g.client.Repositories.GetContents(ctx, owner, repo, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (g *ghWrapper) GetCommitSha(ctx context.Context, owner, repo, ref string) (string, error) {
+	sha, _, err := g.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	return sha, err
+}
+
+// GetCommitDate returns the committer date of ref's commit.
+func (g *ghWrapper) GetCommitDate(ctx context.Context, owner, repo, ref string) (time.Time, error) {
+	// the commit's files aren't needed, so only list one
+	commit, _, err := g.client.Repositories.GetCommit(ctx, owner, repo, ref, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.GetCommit().GetCommitter().GetDate().Time, nil
+}
+
+func (g *ghWrapper) ListCommitFiles(ctx context.Context, owner, repo, sha string) ([]string, error) {
+	var result []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commit, resp, err := g.client.Repositories.GetCommit(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range commit.Files {
+			result = append(result, file.GetFilename())
+			if file.GetPreviousFilename() != "" {
+				result = append(result, file.GetPreviousFilename())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+func (g *ghWrapper) GetPullRequest(ctx context.Context, owner, repo string, number int) (*pullRequest, error) {
+	apiPull, _, err := g.client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	result := pullRequest{
+		Number:  apiPull.GetNumber(),
+		Title:   apiPull.GetTitle(),
+		Body:    apiPull.GetBody(),
+		Labels:  make([]string, len(apiPull.Labels)),
+		State:   apiPull.GetState(),
+		BaseRef: apiPull.GetBase().GetRef(),
+		BaseSha: apiPull.GetBase().GetSHA(),
+		HeadSha: apiPull.GetHead().GetSHA(),
+	}
+	for i, label := range apiPull.Labels {
+		result.Labels[i] = label.GetName()
+	}
+	return &result, nil
+}
+
+func (g *ghWrapper) ListIssueComments(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+	var result []issueComment
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, resp, err := g.client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			result = append(result, issueComment{ID: comment.GetID(), Body: comment.GetBody()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+func (g *ghWrapper) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error {
+	_, _, err := g.client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+	return err
+}
+
+func (g *ghWrapper) EditIssueComment(ctx context.Context, owner, repo string, id int64, body string) error {
+	_, _, err := g.client.Issues.EditComment(ctx, owner, repo, id, &github.IssueComment{Body: &body})
+	return err
+}
+
+func (g *ghWrapper) CreateCheckRun(ctx context.Context, owner, repo string, run *checkRun) error {
+	_, _, err := g.client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:       run.Name,
+		HeadSHA:    run.HeadSha,
+		Status:     github.String("completed"),
+		Conclusion: github.String(run.Conclusion),
+		Output: &github.CheckRunOutput{
+			Title:   github.String(run.Title),
+			Summary: github.String(run.Summary),
+		},
+	})
+	return err
+}
+
+func (g *ghWrapper) ListLabels(ctx context.Context, owner, repo string) ([]repoLabel, error) {
+	var result []repoLabel
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := g.client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			result = append(result, repoLabel{
+				Name:        label.GetName(),
+				Color:       label.GetColor(),
+				Description: label.GetDescription(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+func (g *ghWrapper) CreateLabel(ctx context.Context, owner, repo string, label repoLabel) error {
+	_, _, err := g.client.Issues.CreateLabel(ctx, owner, repo, &github.Label{
+		Name:        &label.Name,
+		Color:       &label.Color,
+		Description: &label.Description,
+	})
+	return err
+}
+
+func (g *ghWrapper) EditLabel(ctx context.Context, owner, repo, name string, label repoLabel) error {
+	_, _, err := g.client.Issues.EditLabel(ctx, owner, repo, name, &github.Label{
+		Name:        &label.Name,
+		Color:       &label.Color,
+		Description: &label.Description,
+	})
+	return err
+}
+
+func (g *ghWrapper) DeleteLabel(ctx context.Context, owner, repo, name string) error {
+	_, err := g.client.Issues.DeleteLabel(ctx, owner, repo, name)
+	return err
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGHWrapper(t *testing.T, handler http.Handler) *ghWrapper {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	var err error
+	client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	return &ghWrapper{client: client}
+}
+
+func Test_ghWrapper_CompareCommits(t *testing.T) {
+	ctx := context.Background()
+	writeJSON := func(t *testing.T, w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+	apiCommits := func(shas ...string) []map[string]any {
+		commits := make([]map[string]any, len(shas))
+		for i, sha := range shas {
+			commits[i] = map[string]any{
+				"sha":    sha,
+				"commit": map[string]string{"message": "message " + sha},
+			}
+		}
+		return commits
+	}
+
+	t.Run("paginated", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/willabides/semver-next/compare/v1.0.0...main", func(w http.ResponseWriter, r *http.Request) {
+			commits := apiCommits("c1", "c2")
+			if r.URL.Query().Get("page") == "2" {
+				commits = apiCommits("c3")
+			} else {
+				w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+			}
+			writeJSON(t, w, map[string]any{
+				"status":        "ahead",
+				"total_commits": 3,
+				"commits":       commits,
+			})
+		})
+		gh := testGHWrapper(t, mux)
+		got, err := gh.CompareCommits(ctx, "willabides", "semver-next", "v1.0.0", "main")
+		require.NoError(t, err)
+		require.Equal(t, &comparison{
+			Status: compareStatusAhead,
+			Commits: []repoCommit{
+				{Sha: "c1", Message: "message c1"},
+				{Sha: "c2", Message: "message c2"},
+				{Sha: "c3", Message: "message c3"},
+			},
+		}, got)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/willabides/semver-next/compare/v1.0.0...main", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, map[string]any{
+				"status":        "ahead",
+				"total_commits": 5,
+				"commits":       apiCommits("c1", "c2"),
+			})
+		})
+		gh := testGHWrapper(t, mux)
+		_, err := gh.CompareCommits(ctx, "willabides", "semver-next", "v1.0.0", "main")
+		require.EqualError(t, err, "comparing v1.0.0...main listed 2 of 5 commits")
+	})
+}
+
+func Test_ghWrapper_ListPullRequestsWithCommit(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/willabides/semver-next/commits/missing/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, err := w.Write([]byte(`{"message": "No commit found for SHA: missing"}`))
+		assert.NoError(t, err)
+	})
+	gh := testGHWrapper(t, mux)
+	_, err := gh.ListPullRequestsWithCommit(ctx, "willabides", "semver-next", "missing")
+	require.ErrorIs(t, err, errCommitNotFound)
+}
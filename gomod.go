@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+const (
+	goModuleCheckOff   = "off"
+	goModuleCheckWarn  = "warn"
+	goModuleCheckError = "error"
+)
+
+// ResultGoModule describes whether a Go module's path matches the major version of the next release.
+type ResultGoModule struct {
+	// Path is the module path in go.mod at the head ref.
+	Path string `json:"path"`
+	// WantPath is the module path required by the next version.
+	WantPath string `json:"want_path"`
+	// PathChangeRequired is true when Path and WantPath differ.
+	PathChangeRequired bool `json:"path_change_required"`
+}
+
+// goModuleWantPath returns modPath with its major version suffix changed to match version. gopkg.in paths keep their
+// ".vN" form.
+func goModuleWantPath(modPath string, version *semver.Version) string {
+	prefix, pathMajor, _ := module.SplitPathVersion(modPath)
+	if module.CheckPathMajor("v"+version.String(), pathMajor) == nil {
+		return modPath
+	}
+	if strings.HasPrefix(modPath, "gopkg.in/") {
+		return fmt.Sprintf("%s.v%d", prefix, version.Major())
+	}
+	if version.Major() < 2 {
+		return prefix
+	}
+	return fmt.Sprintf("%s/v%d", prefix, version.Major())
+}
+
+// checkGoModule reads go.mod from dir at ref and compares its module path to the major version of
+// result.NextVersion. The comparison is recorded in result.GoModule. A mismatch is added to result.Warnings when mode
+// is goModuleCheckWarn and returned as an error when mode is goModuleCheckError.
+func checkGoModule(ctx context.Context, gh wrapper, owner, repo, ref, dir, mode string, result *Result) error {
+	if mode == "" || mode == goModuleCheckOff {
+		return nil
+	}
+	filename := path.Join(dir, "go.mod")
+	content, err := gh.GetFileContent(ctx, owner, repo, filename, ref)
+	if err != nil {
+		return fmt.Errorf("reading %s at %s: %v", filename, ref, err)
+	}
+	modPath := modfile.ModulePath(content)
+	if modPath == "" {
+		return fmt.Errorf("no module path found in %s at %s", filename, ref)
+	}
+	nextVersion, err := semver.NewVersion(result.NextVersion)
+	if err != nil {
+		return err
+	}
+	wantPath := goModuleWantPath(modPath, nextVersion)
+	result.GoModule = &ResultGoModule{
+		Path:               modPath,
+		WantPath:           wantPath,
+		PathChangeRequired: modPath != wantPath,
+	}
+	if !result.GoModule.PathChangeRequired {
+		return nil
+	}
+	var msg string
+	if result.ChangeLevel == changeLevelMajor {
+		msg = fmt.Sprintf("releasing %s requires changing the module path in %s from %s to %s", result.NextVersion, filename, modPath, wantPath)
+	} else {
+		msg = fmt.Sprintf("module path %s in %s does not match version %s; expected %s", modPath, filename, result.NextVersion, wantPath)
+	}
+	if mode == goModuleCheckError {
+		return fmt.Errorf("%s", msg)
+	}
+	result.Warnings = append(result.Warnings, msg)
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkGoModule(t *testing.T) {
+	ctx := context.Background()
+	stub := func(modPath string) *wrapperStub {
+		return &wrapperStub{
+			getFileContent: func(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+				assert.Equal(t, []string{"willabides", "semver-next", "api/go.mod", "main"}, []string{owner, repo, path, ref})
+				return []byte("module " + modPath + "\n\ngo 1.20\n"), nil
+			},
+		}
+	}
+
+	for _, td := range []struct {
+		name        string
+		modPath     string
+		result      Result
+		mode        string
+		wantModule  *ResultGoModule
+		wantWarning string
+		wantErr     string
+	}{
+		{
+			name:    "off",
+			modPath: "example.com/api",
+			result:  Result{NextVersion: "2.0.0", ChangeLevel: changeLevelMajor},
+			mode:    goModuleCheckOff,
+		},
+		{
+			name:       "v0 to v1",
+			modPath:    "example.com/api",
+			result:     Result{NextVersion: "1.0.0", ChangeLevel: changeLevelMajor},
+			mode:       goModuleCheckError,
+			wantModule: &ResultGoModule{Path: "example.com/api", WantPath: "example.com/api"},
+		},
+		{
+			name:    "v1 to v2 warn",
+			modPath: "example.com/api",
+			result:  Result{NextVersion: "2.0.0", ChangeLevel: changeLevelMajor},
+			mode:    goModuleCheckWarn,
+			wantModule: &ResultGoModule{
+				Path: "example.com/api", WantPath: "example.com/api/v2", PathChangeRequired: true,
+			},
+			wantWarning: "releasing 2.0.0 requires changing the module path in api/go.mod from example.com/api to example.com/api/v2",
+		},
+		{
+			name:    "v1 to v2 error",
+			modPath: "example.com/api",
+			result:  Result{NextVersion: "2.0.0", ChangeLevel: changeLevelMajor},
+			mode:    goModuleCheckError,
+			wantErr: "releasing 2.0.0 requires changing the module path in api/go.mod from example.com/api to example.com/api/v2",
+		},
+		{
+			name:       "path already changed",
+			modPath:    "example.com/api/v3",
+			result:     Result{NextVersion: "3.0.0", ChangeLevel: changeLevelMajor},
+			mode:       goModuleCheckError,
+			wantModule: &ResultGoModule{Path: "example.com/api/v3", WantPath: "example.com/api/v3"},
+		},
+		{
+			name:    "v2 minor with v1 path",
+			modPath: "example.com/api",
+			result:  Result{NextVersion: "2.1.0", ChangeLevel: changeLevelMinor},
+			mode:    goModuleCheckError,
+			wantErr: "module path example.com/api in api/go.mod does not match version 2.1.0; expected example.com/api/v2",
+		},
+		{
+			name:       "gopkg.in minor",
+			modPath:    "gopkg.in/api.v3",
+			result:     Result{NextVersion: "3.1.0", ChangeLevel: changeLevelMinor},
+			mode:       goModuleCheckError,
+			wantModule: &ResultGoModule{Path: "gopkg.in/api.v3", WantPath: "gopkg.in/api.v3"},
+		},
+		{
+			name:    "gopkg.in v3 to v4",
+			modPath: "gopkg.in/api.v3",
+			result:  Result{NextVersion: "4.0.0", ChangeLevel: changeLevelMajor},
+			mode:    goModuleCheckError,
+			wantErr: "releasing 4.0.0 requires changing the module path in api/go.mod from gopkg.in/api.v3 to gopkg.in/api.v4",
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			result := td.result
+			err := checkGoModule(ctx, stub(td.modPath), "willabides", "semver-next", "main", "api", td.mode, &result)
+			if td.wantErr != "" {
+				require.EqualError(t, err, td.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, td.wantModule, result.GoModule)
+			if td.wantWarning == "" {
+				require.Empty(t, result.Warnings)
+			} else {
+				require.Equal(t, []string{td.wantWarning}, result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type historyCmd struct {
+	Repo   string `kong:"arg,required,help=${repo_help}"`
+	Scheme string `kong:"help=${scheme_help},default=semver"`
+	Json   bool   `kong:"help=Output in JSON format"`
+}
+
+func (c *historyCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	if len(cfg.Packages) > 0 {
+		return fmt.Errorf("history doesn't support packages; remove them from the config file to audit the repository's unprefixed release tags")
+	}
+	scheme, err := parseVersionScheme(c.Scheme)
+	if err != nil {
+		return err
+	}
+	releases, err := history(ctx, historyOptions{
+		gh:     gh,
+		repo:   c.Repo,
+		rules:  cfg.pullRules(),
+		scheme: scheme,
+	})
+	if err != nil {
+		return err
+	}
+	if c.Json {
+		return printJSON(releases)
+	}
+	matches := 0
+	for _, r := range releases {
+		fmt.Println(r.String())
+		if r.Match {
+			matches++
+		}
+	}
+	fmt.Printf("%d of %d releases match\n", matches, len(releases))
+	return nil
+}
+
+// HistoryRelease compares the version computed for a past release to the version it was released as.
+type HistoryRelease struct {
+	Tag string `json:"tag"`
+
+	// PreviousTag is the highest lower release tag that Tag descends from.
+	PreviousTag     string      `json:"previous_tag,omitempty"`
+	ComputedVersion string      `json:"computed_version,omitempty"`
+	ChangeLevel     changeLevel `json:"change_level"`
+	Match           bool        `json:"match"`
+
+	// Error is why the version couldn't be computed, such as pull requests with no semver labels.
+	Error string `json:"error,omitempty"`
+}
+
+func (r *HistoryRelease) String() string {
+	switch {
+	case r.Error != "" && r.PreviousTag == "":
+		return fmt.Sprintf("%s: error: %s", r.Tag, r.Error)
+	case r.Error != "":
+		return fmt.Sprintf("%s (from %s): error: %s", r.Tag, r.PreviousTag, r.Error)
+	case r.Match:
+		return fmt.Sprintf("%s (from %s): match", r.Tag, r.PreviousTag)
+	default:
+		return fmt.Sprintf("%s (from %s): differs, computed %s (%s)", r.Tag, r.PreviousTag, r.ComputedVersion, r.ChangeLevel)
+	}
+}
+
+type historyOptions struct {
+	gh    wrapper
+	repo  string
+	rules *pullRules
+
+	// scheme parses release tags. nil uses semverScheme.
+	scheme versionScheme
+}
+
+// history computes the version of each release tag from the highest lower release tag it descends from and compares
+// it to the tag. Prerelease tags are skipped. Releases whose version can't be computed are reported with an Error
+// instead of failing the whole history.
+func history(ctx context.Context, opts historyOptions) ([]HistoryRelease, error) {
+	scheme := opts.scheme
+	if scheme == nil {
+		scheme = semverScheme{}
+	}
+	owner, repo, err := splitRepo(opts.repo)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := opts.gh.ListTags(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	type taggedVersion struct {
+		tag     string
+		version schemeVersion
+	}
+	var versions []taggedVersion
+	for _, tag := range tags {
+		v, err := scheme.parse(tag)
+		if err != nil || v.prerelease() {
+			continue
+		}
+		versions = append(versions, taggedVersion{tag: tag, version: v})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].version.compare(versions[j].version) < 0
+	})
+	// keep one tag per version when there are several, such as both v1.2.0 and 1.2.0
+	unique := versions[:0]
+	for _, v := range versions {
+		if len(unique) == 0 || v.version.compare(unique[len(unique)-1].version) != 0 {
+			unique = append(unique, v)
+		}
+	}
+	versions = unique
+
+	var releases []HistoryRelease
+	for i := 1; i < len(versions); i++ {
+		cur := versions[i]
+		// A release on a maintenance branch doesn't descend from higher versions released from other branches, so
+		// the previous release is the highest lower version that cur descends from.
+		prevIdx := -1
+		var prevComp *comparison
+		for j := i - 1; j >= 0 && prevIdx == -1; j-- {
+			comp, err := opts.gh.CompareCommits(ctx, owner, repo, versions[j].tag, cur.tag)
+			if err != nil {
+				return nil, err
+			}
+			if comp.Status == compareStatusAhead || comp.Status == compareStatusIdentical {
+				prevIdx, prevComp = j, comp
+			}
+		}
+		release := HistoryRelease{Tag: cur.tag}
+		if prevIdx == -1 {
+			release.Error = fmt.Sprintf("%s doesn't descend from any lower release tag", cur.tag)
+			releases = append(releases, release)
+			continue
+		}
+		prev := versions[prevIdx]
+		release.PreviousTag = prev.tag
+		releaseScheme := scheme
+		if dated, ok := scheme.(datedScheme); ok {
+			// the release was computed on the day it was tagged, not today
+			date, err := opts.gh.GetCommitDate(ctx, owner, repo, cur.tag)
+			if err != nil {
+				return nil, err
+			}
+			releaseScheme = dated.at(date)
+		}
+		result, err := next(ctx, nextOptions{
+			gh:             opts.gh,
+			repo:           opts.repo,
+			base:           prev.tag,
+			head:           cur.tag,
+			prevVersion:    prev.version.String(),
+			scheme:         releaseScheme,
+			ignoreExisting: true,
+			tags:           tags,
+			comparison:     prevComp,
+			rules:          opts.rules,
+		})
+		if err != nil {
+			release.Error = err.Error()
+			releases = append(releases, release)
+			continue
+		}
+		release.ComputedVersion = result.NextVersion
+		release.ChangeLevel = result.ChangeLevel
+		computed, err := scheme.parse(result.NextVersion)
+		if err != nil {
+			return nil, err
+		}
+		release.Match = computed.compare(cur.version) == 0
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
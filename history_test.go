@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_history(t *testing.T) {
+	ctx := context.Background()
+
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha2 := "2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha3 := "3aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	listTagsCalls := 0
+	comparisons := map[string]int{}
+	gh := wrapperStub{
+		listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+			listTagsCalls++
+			return []string{"v1.2.0", "v1.1.0-rc.1", "v1.1.0", "1.1.0", "v1.0.0", "foo/v3.0.0", "latest"}, nil
+		},
+		compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+			comparisons[base+"..."+head]++
+			switch base + "..." + head {
+			case "v1.0.0...v1.1.0":
+				return []string{sha1}, nil
+			case "v1.1.0...v1.2.0":
+				return []string{sha2, sha3}, nil
+			}
+			t.Errorf("unexpected comparison %s...%s", base, head)
+			return nil, nil
+		},
+		listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+			{
+				owner: "willabides", repo: "semver-next", sha: sha1,
+				result: []ResultPull{{Number: 1, Labels: []string{"enhancement"}}},
+			},
+			{
+				owner: "willabides", repo: "semver-next", sha: sha2,
+				result: []ResultPull{{Number: 2, Labels: []string{"bug"}}},
+			},
+			{
+				owner: "willabides", repo: "semver-next", sha: sha3,
+				result: []ResultPull{{Number: 3, Labels: []string{"bug"}}},
+			},
+		}),
+	}
+	got, err := history(ctx, historyOptions{
+		gh:   &gh,
+		repo: "willabides/semver-next",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []HistoryRelease{
+		{
+			Tag:             "v1.1.0",
+			PreviousTag:     "v1.0.0",
+			ComputedVersion: "1.1.0",
+			ChangeLevel:     changeLevelMinor,
+			Match:           true,
+		},
+		{
+			Tag:             "v1.2.0",
+			PreviousTag:     "v1.1.0",
+			ComputedVersion: "1.1.1",
+			ChangeLevel:     changeLevelPatch,
+		},
+	}, got)
+	// tags are listed once and each pair is compared once
+	assert.Equal(t, 1, listTagsCalls)
+	assert.Equal(t, map[string]int{"v1.0.0...v1.1.0": 1, "v1.1.0...v1.2.0": 1}, comparisons)
+	assert.Equal(t, "v1.1.0 (from v1.0.0): match", got[0].String())
+	assert.Equal(t, "v1.2.0 (from v1.1.0): differs, computed 1.1.1 (patch)", got[1].String())
+
+	t.Run("maintenance releases", func(t *testing.T) {
+		gh := wrapperStub{
+			listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+				return []string{"v1.4.0", "v1.4.1", "v2.0.0", "v1.4.2"}, nil
+			},
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				switch base + "..." + head {
+				case "v1.4.0...v1.4.1", "v1.4.1...v1.4.2":
+					return []string{sha1}, nil
+				case "v1.4.0...v2.0.0":
+					return []string{sha2}, nil
+				}
+				return []string{sha3}, nil
+			},
+			// v1.4.1 and v1.4.2 are on the release/1.4.x branch
+			compareStatuses: map[string]string{
+				"v1.4.2...v2.0.0": compareStatusDiverged,
+				"v1.4.1...v2.0.0": compareStatusDiverged,
+			},
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{owner: "willabides", repo: "semver-next", sha: sha1, result: []ResultPull{{Number: 1, Labels: []string{"bug"}}}},
+				{owner: "willabides", repo: "semver-next", sha: sha1, result: []ResultPull{{Number: 1, Labels: []string{"bug"}}}},
+				{owner: "willabides", repo: "semver-next", sha: sha2, result: []ResultPull{{Number: 2, Labels: []string{"breaking"}}}},
+			}),
+		}
+		got, err := history(ctx, historyOptions{
+			gh:   &gh,
+			repo: "willabides/semver-next",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []HistoryRelease{
+			{Tag: "v1.4.1", PreviousTag: "v1.4.0", ComputedVersion: "1.4.1", ChangeLevel: changeLevelPatch, Match: true},
+			{Tag: "v1.4.2", PreviousTag: "v1.4.1", ComputedVersion: "1.4.2", ChangeLevel: changeLevelPatch, Match: true},
+			{Tag: "v2.0.0", PreviousTag: "v1.4.0", ComputedVersion: "2.0.0", ChangeLevel: changeLevelMajor, Match: true},
+		}, got)
+	})
+
+	t.Run("scheme", func(t *testing.T) {
+		gh := labeledPullStub(t, sha1, "enhancement").withTags("1.2", "1.3", "1.4rc1")
+		got, err := history(ctx, historyOptions{
+			gh:     gh,
+			repo:   "willabides/semver-next",
+			scheme: pep440Scheme{},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []HistoryRelease{
+			{Tag: "1.3", PreviousTag: "1.2", ComputedVersion: "1.3.0", ChangeLevel: changeLevelMinor, Match: true},
+		}, got)
+	})
+
+	t.Run("calver", func(t *testing.T) {
+		scheme, err := newCalverScheme("YYYY.0M.MICRO", nil)
+		require.NoError(t, err)
+		tagDates := map[string]time.Time{
+			"2024.01.1": time.Date(2024, time.January, 20, 12, 0, 0, 0, time.UTC),
+			"2024.03.0": time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC),
+		}
+		gh := wrapperStub{
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				if head == "2024.01.1" {
+					return []string{sha1}, nil
+				}
+				return []string{sha2}, nil
+			},
+			getCommitDate: func(ctx context.Context, owner, repo, ref string) (time.Time, error) {
+				return tagDates[ref], nil
+			},
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{owner: "willabides", repo: "semver-next", sha: sha1, result: []ResultPull{{Number: 1, Labels: []string{"bug"}}}},
+				{owner: "willabides", repo: "semver-next", sha: sha2, result: []ResultPull{{Number: 2, Labels: []string{"bug"}}}},
+			}),
+		}
+		got, err := history(ctx, historyOptions{
+			gh:     gh.withTags("2024.01.0", "2024.01.1", "2024.03.0"),
+			repo:   "willabides/semver-next",
+			scheme: scheme,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []HistoryRelease{
+			{Tag: "2024.01.1", PreviousTag: "2024.01.0", ComputedVersion: "2024.01.1", ChangeLevel: changeLevelPatch, Match: true},
+			{Tag: "2024.03.0", PreviousTag: "2024.01.1", ComputedVersion: "2024.03.0", ChangeLevel: changeLevelPatch, Match: true},
+		}, got)
+	})
+
+	t.Run("no ancestor", func(t *testing.T) {
+		gh := wrapperStub{
+			listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+				return []string{"v1.0.0", "v1.0.1"}, nil
+			},
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				return []string{}, nil
+			},
+			compareStatus: compareStatusDiverged,
+		}
+		got, err := history(ctx, historyOptions{
+			gh:   &gh,
+			repo: "willabides/semver-next",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []HistoryRelease{
+			{Tag: "v1.0.1", Error: "v1.0.1 doesn't descend from any lower release tag"},
+		}, got)
+		require.Equal(t, "v1.0.1: error: v1.0.1 doesn't descend from any lower release tag", got[0].String())
+	})
+}
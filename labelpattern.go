@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// labelPattern matches labels with a regular expression or glob.
+type labelPattern struct {
+	regex *regexp.Regexp
+	glob  string
+	level changeLevel
+}
+
+func newLabelPattern(cfg *labelPatternConfig) (*labelPattern, error) {
+	if (cfg.Regex == "") == (cfg.Glob == "") {
+		return nil, fmt.Errorf("exactly one of regex or glob is required")
+	}
+	level, err := parseChangeLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	p := labelPattern{level: level}
+	if cfg.Regex != "" {
+		p.regex, err = regexp.Compile("(?i)" + cfg.Regex)
+		if err != nil {
+			return nil, err
+		}
+		return &p, nil
+	}
+	p.glob = strings.ToLower(cfg.Glob)
+	_, err = path.Match(p.glob, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %v", cfg.Glob, err)
+	}
+	return &p, nil
+}
+
+// match reports whether the lowercase label matches. Globs use path.Match syntax, so "*" doesn't match "/".
+func (p *labelPattern) match(label string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(label)
+	}
+	ok, err := path.Match(p.glob, label)
+	return err == nil && ok
+}
+
+func (p *labelPattern) String() string {
+	if p.regex != nil {
+		return "regex:" + strings.TrimPrefix(p.regex.String(), "(?i)")
+	}
+	return "glob:" + p.glob
+}
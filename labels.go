@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type labelsCmd struct {
+	Sync labelsSyncCmd `kong:"cmd,help=${labels_sync_help}"`
+}
+
+type labelsSyncCmd struct {
+	Repo   string `kong:"arg,required,help=${repo_help}"`
+	DryRun bool   `kong:"help=${dry_run_help}"`
+	Prune  bool   `kong:"help=${prune_help}"`
+}
+
+func (c *labelsSyncCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	owner, repo, err := splitRepo(c.Repo)
+	if err != nil {
+		return err
+	}
+	existing, err := gh.ListLabels(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	changes := planLabelSync(existing, desiredLabels(cfg), c.Prune, recognizedLabel(cfg))
+	for _, change := range changes {
+		if c.DryRun {
+			fmt.Printf("would %s\n", change)
+			continue
+		}
+		err = change.apply(ctx, gh, owner, repo)
+		if err != nil {
+			return err
+		}
+		fmt.Println(change)
+	}
+	return nil
+}
+
+var levelLabelColors = map[changeLevel]string{
+	changeLevelMajor:    "b60205",
+	changeLevelMinor:    "fbca04",
+	changeLevelPatch:    "0e8a16",
+	changeLevelNoChange: "cfd3d7",
+}
+
+var levelLabelDescriptions = map[changeLevel]string{
+	changeLevelMajor:    "semver-next: major version bump for breaking changes",
+	changeLevelMinor:    "semver-next: minor version bump for new features",
+	changeLevelPatch:    "semver-next: patch version bump for fixes",
+	changeLevelNoChange: "semver-next: no version bump",
+}
+
+// overrideLabelColor is the color of labels that force or cap the change level of a release.
+const overrideLabelColor = "5319e7"
+
+func newLevelLabel(name string, level changeLevel) repoLabel {
+	return repoLabel{
+		Name:        name,
+		Color:       levelLabelColors[level],
+		Description: levelLabelDescriptions[level],
+	}
+}
+
+// desiredLabels returns the labels semver-next manages: one "semver:<level>" label per change level, the override
+// labels, the labels from the config file and one "semver:<namespace>:<level>" label per change level for each
+// configured package.
+func desiredLabels(cfg *config) []repoLabel {
+	levels := []changeLevel{changeLevelMajor, changeLevelMinor, changeLevelPatch, changeLevelNoChange}
+	levelNames := map[changeLevel]string{
+		changeLevelMajor:    "major",
+		changeLevelMinor:    "minor",
+		changeLevelPatch:    "patch",
+		changeLevelNoChange: "none",
+	}
+	var result []repoLabel
+	for _, level := range levels {
+		result = append(result, newLevelLabel("semver:"+levelNames[level], level))
+	}
+	for _, name := range overrideLabels {
+		kind, level, _ := parseOverrideLabel(name)
+		description := fmt.Sprintf("semver-next: release at least a %s change", level)
+		if kind == overrideCap {
+			description = fmt.Sprintf("semver-next: release at most a %s change", level)
+		}
+		result = append(result, repoLabel{Name: name, Color: overrideLabelColor, Description: description})
+	}
+	for _, name := range sortedKeys(cfg.Labels) {
+		result = append(result, newLevelLabel(name, cfg.labelLevels[strings.ToLower(name)]))
+	}
+	for _, pkg := range cfg.Packages {
+		for _, level := range levels {
+			result = append(result, newLevelLabel(fmt.Sprintf("semver:%s:%s", pkg.labelNamespace(), levelNames[level]), level))
+		}
+	}
+	return result
+}
+
+type labelChange struct {
+	action string
+	// name is the existing name of the label to update or delete.
+	name  string
+	label repoLabel
+}
+
+func (c labelChange) String() string {
+	if c.action == "delete" {
+		return fmt.Sprintf("delete label %q", c.name)
+	}
+	return fmt.Sprintf("%s label %q", c.action, c.label.Name)
+}
+
+func (c labelChange) apply(ctx context.Context, gh wrapper, owner, repo string) error {
+	switch c.action {
+	case "create":
+		return gh.CreateLabel(ctx, owner, repo, c.label)
+	case "update":
+		return gh.EditLabel(ctx, owner, repo, c.name, c.label)
+	case "delete":
+		return gh.DeleteLabel(ctx, owner, repo, c.name)
+	default:
+		return fmt.Errorf("unknown label action %q", c.action)
+	}
+}
+
+// recognizedLabel returns a func reporting whether a label means something to semver-next with cfg. This includes
+// labels like "semver:skip" that labels sync doesn't create.
+func recognizedLabel(cfg *config) func(label string) bool {
+	levelFuncs := []labelLevelFunc{cfg.labelLevel}
+	for _, pkg := range cfg.Packages {
+		levelFuncs = append(levelFuncs, packageLabelLevel(pkg.labelNamespace(), cfg.labelLevel))
+	}
+	levelFor := anyLabelLevel(levelFuncs)
+	return func(label string) bool {
+		label = strings.ToLower(label)
+		if _, _, ok := parseOverrideLabel(label); ok {
+			return true
+		}
+		_, ok := levelFor(label)
+		return ok
+	}
+}
+
+// planLabelSync returns the changes needed to make existing labels match desired. Label names are compared case
+// insensitively like GitHub does. When prune is true, existing labels starting with "semver:" that aren't desired are
+// deleted unless recognized reports them as meaningful. Deleting a label removes it from every pull request, so a
+// recognized label is never pruned.
+func planLabelSync(existing, desired []repoLabel, prune bool, recognized func(label string) bool) []labelChange {
+	existingByName := make(map[string]repoLabel, len(existing))
+	for _, l := range existing {
+		existingByName[strings.ToLower(l.Name)] = l
+	}
+	desiredNames := make(map[string]bool, len(desired))
+	var changes []labelChange
+	for _, want := range desired {
+		key := strings.ToLower(want.Name)
+		if desiredNames[key] {
+			continue
+		}
+		desiredNames[key] = true
+		got, ok := existingByName[key]
+		switch {
+		case !ok:
+			changes = append(changes, labelChange{action: "create", label: want})
+		case got != want:
+			changes = append(changes, labelChange{action: "update", name: got.Name, label: want})
+		}
+	}
+	if !prune {
+		return changes
+	}
+	var obsolete []string
+	for key, l := range existingByName {
+		if strings.HasPrefix(key, "semver:") && !desiredNames[key] && !recognized(l.Name) {
+			obsolete = append(obsolete, l.Name)
+		}
+	}
+	sort.Strings(obsolete)
+	for _, name := range obsolete {
+		changes = append(changes, labelChange{action: "delete", name: name})
+	}
+	return changes
+}
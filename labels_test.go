@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_desiredLabels(t *testing.T) {
+	cfg := config{
+		Labels:   map[string]string{"Impact: Breaking": "major"},
+		Packages: []packageConfig{{Name: "api"}},
+	}
+	require.NoError(t, cfg.validate())
+	var names []string
+	for _, l := range desiredLabels(&cfg) {
+		names = append(names, l.Name)
+	}
+	require.Equal(t, []string{
+		"semver:major", "semver:minor", "semver:patch", "semver:none",
+		"semver:force-major", "semver:force-minor", "semver:force-patch",
+		"semver:cap-minor", "semver:cap-patch", "semver:cap-none",
+		"Impact: Breaking",
+		"semver:api:major", "semver:api:minor", "semver:api:patch", "semver:api:none",
+	}, names)
+}
+
+func Test_planLabelSync(t *testing.T) {
+	major := newLevelLabel("semver:major", changeLevelMajor)
+	minor := newLevelLabel("semver:minor", changeLevelMinor)
+	patch := newLevelLabel("semver:patch", changeLevelPatch)
+	existing := []repoLabel{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "SEMVER:MAJOR", Color: major.Color, Description: major.Description},
+		minor,
+		{Name: "semver:skip", Color: "ffffff"},
+		{Name: "semver:old", Color: "ffffff"},
+	}
+	desired := []repoLabel{major, minor, patch}
+	recognized := recognizedLabel(&config{})
+
+	t.Run("without prune", func(t *testing.T) {
+		got := planLabelSync(existing, desired, false, recognized)
+		require.Equal(t, []labelChange{
+			{action: "update", name: "SEMVER:MAJOR", label: major},
+			{action: "create", label: patch},
+		}, got)
+		require.Equal(t, `update label "semver:major"`, got[0].String())
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		got := planLabelSync(existing, desired, true, recognized)
+		require.Equal(t, []labelChange{
+			{action: "update", name: "SEMVER:MAJOR", label: major},
+			{action: "create", label: patch},
+			{action: "delete", name: "semver:old"},
+		}, got)
+		require.Equal(t, `delete label "semver:old"`, got[2].String())
+	})
+
+	t.Run("prune keeps recognized labels", func(t *testing.T) {
+		cfg := config{
+			Labels:        map[string]string{"semver:docs": "none"},
+			LabelPatterns: []labelPatternConfig{{Glob: "semver:feat-*", Level: "minor"}},
+			Packages:      []packageConfig{{Name: "api"}},
+		}
+		require.NoError(t, cfg.validate())
+		existing := []repoLabel{
+			major, minor, patch,
+			{Name: "semver:skip"},
+			{Name: "semver:nochange"},
+			{Name: "semver:no change"},
+			{Name: "Semver:Docs"},
+			{Name: "semver:feat-ui"},
+			{Name: "semver:force-major"},
+			{Name: "semver:api:skip"},
+			{Name: "semver:old"},
+		}
+		got := planLabelSync(existing, desired, true, recognizedLabel(&cfg))
+		require.Equal(t, []labelChange{
+			{action: "delete", name: "semver:old"},
+		}, got)
+	})
+}
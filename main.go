@@ -17,8 +17,8 @@ var version = "unknown"
 var kongVars = kong.Vars{
 	"repo_help": `GitHub repository in "<owner>/<repo>" format. e.g. WillAbides/semver-next`,
 
-	"prev_tag_help": `The git tag from the previous release. This should rarely be needed. When this is unset, it uses 
-the tag of the release marked "latest release" on the GitHub releases page.`,
+	"prev_tag_help": `The git tag from the previous release. Required unless --ref is a maintenance branch like 
+release/1.4.x, where it defaults to the latest tag in the branch's release line.`,
 
 	"prev_version_help": `The version of the previous release in semver format. This may be necessary when release tags 
 don't follow semver format.`,
@@ -34,8 +34,92 @@ don't follow semver format.`,
 	"min_bump_help": `The maximum amount to bump the version. This will
 be ignored when there are no commits between the previous release and the target ref.`,
 
+	"config_help": `Path to a semver-next config file.`,
+
+	"packages_help": `Output a version for each package declared in the config file. Each package is compared from 
+its latest release tag. Packages with no release tag are compared from --prev-ref.`,
+
+	"go_module_help": `Check that the go.mod module path at --ref has the major version suffix required by the next 
+version. "warn" reports a mismatch as a warning. "error" fails instead.`,
+
+	"go_module_enum": `off,warn,error`,
+
+	"conflicting_labels_help": `How to handle a pull request with labels for different change levels. "max" uses the highest 
+level. "warn" also reports the pull request as a warning. "error" fails instead.`,
+
+	"conflicting_labels_enum": `max,warn,error`,
+
+	"scheme_help": `The versioning scheme of release versions. One of "semver", "pep440", "four-part" or "calver:<format>" 
+where format is dot separated calver.org date tokens ending with MICRO or PATCH. e.g. "calver:YYYY.0M.MICRO"`,
+
+	"strict_help": `Fail instead of warning when the tag for --prev-version doesn't point at the same commit as 
+--prev-ref.`,
+
+	"auto_skip_help": `When the next version already has a tag, keep bumping until reaching a version without one instead 
+of failing.`,
+
+	"explain_help": `Output a human-readable explanation of how the next version was chosen instead of the version.`,
+
+	"go_apidiff_help": `Compare the exported API of the Go packages at --prev-ref and --ref in the local checkout. Incompatible 
+changes raise the change level to major and compatible additions raise it to minor.`,
+
+	"checkout_help": `Path to a local git checkout of the repository. Only used by --go-apidiff.`,
+
+	"next_help": `Output the next release version. This is the default command.`,
+
+	"check_help": `Fail when pull request labels understate the changes to exported Go APIs in the local checkout.`,
+
+	"check_ref_help": `The tag, branch or commit sha with the changes to check.`,
+
+	"check_prev_ref_help": `The tag, branch or commit sha to compare --ref to.`,
+
+	"check_checkout_help": `Path to a local git checkout of the repository.`,
+
+	"check_pr_help": `Validate the semver labels on a pull request and output what the next release version would be if 
+it were merged.`,
+
+	"comment_pr_help": `Create or update a comment on a pull request showing what the next release version would be if 
+it were merged.`,
+
+	"check_run_help": `Create a "semver-next" check run on a pull request's head commit. The check fails when the pull 
+request's semver labels are missing or conflicting.`,
+
+	"labels_help": `Manage the semver labels in a repository.`,
+
+	"labels_sync_help": `Create or update the semver labels in a repository with consistent colors and descriptions. This 
+includes a semver:<level> label for each change level, labels from the config file and namespaced labels for each 
+package in the config file.`,
+
+	"dry_run_help": `Output the changes that would be made without making them.`,
+
+	"prune_help": `Delete labels starting with "semver:" that semver-next doesn't recognize. Recognized labels like 
+"semver:skip" are kept.`,
+
+	"serve_help": `Run a server that receives GitHub pull_request webhooks and validates the labels of pull requests 
+as they are opened, edited, updated or relabeled.`,
+
+	"addr_help": `The address to listen on.`,
+
+	"webhook_secret_help": `The secret used to verify webhook signatures.`,
+
+	"serve_comment_help": `Create or update a comment on the pull request showing the next version.`,
+
+	"serve_check_run_help": `Create a "semver-next" check run on the pull request's head commit.`,
+
+	"pull_number_help": `The pull request number.`,
+
+	"pull_prev_ref_help": `The git tag from the previous release. Defaults to the repository's highest semver tag.`,
+
+	"pull_help": `Check the labels of this pull request instead of the merged pull requests between --prev-ref and 
+--ref. --prev-ref and --ref default to the pull request's base and head.`,
+
+	"history_help": `Compute the version of each past release from the highest lower release it descends from and report
+whether it matches the release's tag. Releases are the repository's tags that are valid in --scheme, excluding
+prereleases. Configs with packages aren't supported.`,
+
 	"show_labels_help": `Output the labels semver-next uses to determine the change level of a pull request. Labels are
-output as a JSON object where the key is the label name and the value is the change level.`,
+output as a JSON object where the key is the label name and the value is the change level. Label patterns from the 
+config file are keyed by "regex:<pattern>" or "glob:<pattern>".`,
 }
 
 var mainHelp = `
@@ -44,16 +128,119 @@ latest release to determine the next release version based on pull request label
 `
 
 type cmd struct {
-	Repo        string         `kong:"arg,required,help=${repo_help}"`
-	Ref         string         `kong:"required,short=r,help=${ref_help}"`
-	PrevRef     string         `kong:"prev,required,short=p,help=${prev_tag_help}"`
-	PrevVersion string         `kong:"prev-version,short=v,help=${prev_version_help}"`
-	MaxBump     string         `kong:"enum=${bump_enum},help=${max_bump_help},default=major"`
-	MinBump     string         `kong:"enum=${bump_enum},help=${max_bump_help},default=none"`
 	GithubToken string         `kong:"required,hidden,env=GITHUB_TOKEN"`
+	Config      string         `kong:"type=existingfile,help=${config_help}"`
 	ShowLabels  showLabelsFlag `kong:"help=${show_labels_help}"`
 	Version     versionFlag    `kong:"help=${version_help}"`
-	Json        bool           `kong:"help=Output in JSON format"`
+
+	Next      nextCmd      `kong:"cmd,default=withargs,help=${next_help}"`
+	Check     checkCmd     `kong:"cmd,help=${check_help}"`
+	CheckPR   checkPRCmd   `kong:"cmd,name=check-pr,help=${check_pr_help}"`
+	CommentPR commentPRCmd `kong:"cmd,name=comment-pr,help=${comment_pr_help}"`
+	CheckRun  checkRunCmd  `kong:"cmd,name=check-run,help=${check_run_help}"`
+	Labels    labelsCmd    `kong:"cmd,help=${labels_help}"`
+	Serve     serveCmd     `kong:"cmd,help=${serve_help}"`
+	History   historyCmd   `kong:"cmd,help=${history_help}"`
+}
+
+type nextCmd struct {
+	Repo              string `kong:"arg,required,help=${repo_help}"`
+	Ref               string `kong:"required,short=r,help=${ref_help}"`
+	PrevRef           string `kong:"prev,short=p,help=${prev_tag_help}"`
+	PrevVersion       string `kong:"prev-version,short=v,help=${prev_version_help}"`
+	MaxBump           string `kong:"enum=${bump_enum},help=${max_bump_help},default=major"`
+	MinBump           string `kong:"enum=${bump_enum},help=${max_bump_help},default=none"`
+	Packages          bool   `kong:"help=${packages_help}"`
+	GoModule          string `kong:"enum=${go_module_enum},help=${go_module_help},default=off"`
+	GoAPIDiff         bool   `kong:"name=go-apidiff,help=${go_apidiff_help}"`
+	Checkout          string `kong:"type=existingdir,help=${checkout_help},default=."`
+	ConflictingLabels string `kong:"enum=${conflicting_labels_enum},help=${conflicting_labels_help},default=max"`
+	Scheme            string `kong:"help=${scheme_help},default=semver"`
+	Strict            bool   `kong:"help=${strict_help}"`
+	AutoSkip          bool   `kong:"help=${auto_skip_help}"`
+	Explain           bool   `kong:"xor=output,help=${explain_help}"`
+	Json              bool   `kong:"xor=output,help=Output in JSON format"`
+}
+
+func (c *nextCmd) Run(ctx context.Context, gh wrapper, cfg *config) error {
+	scheme, err := parseVersionScheme(c.Scheme)
+	if err != nil {
+		return err
+	}
+	opts := nextOptions{
+		rules:               cfg.pullRules(),
+		repo:                c.Repo,
+		gh:                  gh,
+		prevVersion:         c.PrevVersion,
+		base:                c.PrevRef,
+		head:                c.Ref,
+		minBump:             c.MinBump,
+		maxBump:             c.MaxBump,
+		goModule:            c.GoModule,
+		goAPIDiff:           c.GoAPIDiff,
+		checkout:            c.Checkout,
+		conflictingLabels:   c.ConflictingLabels,
+		maintenanceBranches: cfg.maintenanceBranches,
+		scheme:              scheme,
+		strict:              c.Strict,
+		autoSkip:            c.AutoSkip,
+	}
+	if c.PrevRef == "" && (c.Packages || findMaintenanceLine(c.Ref, cfg.maintenanceBranches) == nil) {
+		return fmt.Errorf("--prev-ref is required unless --ref is a maintenance branch")
+	}
+	if c.Packages {
+		if _, ok := scheme.(semverScheme); !ok {
+			return fmt.Errorf("--packages requires the semver version scheme")
+		}
+		return c.runPackages(ctx, opts, cfg)
+	}
+	res, err := next(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if c.Explain {
+		return explainResult(os.Stdout, res, opts.rules)
+	}
+	printWarnings(res.Warnings)
+	if !c.Json {
+		fmt.Println(res.NextVersion)
+		return nil
+	}
+	return printJSON(res)
+}
+
+func (c *nextCmd) runPackages(ctx context.Context, opts nextOptions, cfg *config) error {
+	if len(cfg.Packages) == 0 {
+		return fmt.Errorf("--packages requires a config file declaring packages")
+	}
+	res, err := nextPackages(ctx, opts, cfg.Packages)
+	if err != nil {
+		return err
+	}
+	if c.Explain {
+		for i, r := range res {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("package %s\n", r.Package)
+			levelFor := packageLabelLevel(cfg.Packages[i].labelNamespace(), opts.rules.levelForLabel)
+			err = explainResult(os.Stdout, &r.Result, opts.rules.withLabelLevel(levelFor))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, r := range res {
+		printWarnings(r.Warnings)
+	}
+	if !c.Json {
+		for _, r := range res {
+			fmt.Printf("%s %s\n", r.Package, r.NextVersion)
+		}
+		return nil
+	}
+	return printJSON(res)
 }
 
 type versionFlag bool
@@ -67,9 +254,20 @@ func (d versionFlag) BeforeApply(k *kong.Context) error {
 type showLabelsFlag bool
 
 func (d showLabelsFlag) BeforeApply(k *kong.Context) error {
+	// flags aren't applied yet, so --config has to be read from the parse trace
+	cfg := &config{}
+	for _, flag := range k.Flags() {
+		filename, ok := k.FlagValue(flag).(string)
+		if flag.Name != "config" || !ok || filename == "" {
+			continue
+		}
+		var err error
+		cfg, err = loadConfig(filename)
+		k.FatalIfErrorf(err)
+	}
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	err := enc.Encode(labelLevels)
+	err := enc.Encode(cfg.displayLabels())
 	if err != nil {
 		k.FatalIfErrorf(err)
 	}
@@ -86,25 +284,28 @@ func main() {
 	oauthClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cli.GithubToken}))
 	rateLimitClient, err := github_ratelimit.NewRateLimitWaiterClient(oauthClient.Transport)
 	k.FatalIfErrorf(err)
-	client := github.NewClient(rateLimitClient)
-	res, err := next(
-		ctx,
-		nextOptions{
-			repo:        cli.Repo,
-			gh:          &ghWrapper{client: client},
-			prevVersion: cli.PrevVersion,
-			base:        cli.PrevRef,
-			head:        cli.Ref,
-			minBump:     cli.MinBump,
-			maxBump:     cli.MaxBump,
-		},
-	)
-	k.FatalIfErrorf(err)
-	if !cli.Json {
-		fmt.Println(res.NextVersion)
-		return
+	cfg := &config{}
+	if cli.Config != "" {
+		cfg, err = loadConfig(cli.Config)
+		k.FatalIfErrorf(err)
+	}
+	k.Bind(cfg)
+	k.BindTo(ctx, (*context.Context)(nil))
+	k.BindTo(&ghWrapper{client: github.NewClient(rateLimitClient)}, (*wrapper)(nil))
+	k.FatalIfErrorf(k.Run())
+}
+
+func printJSON(v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
 	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	k.FatalIfErrorf(err)
 	fmt.Println(string(b))
+	return nil
+}
+
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
 }
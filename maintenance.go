@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// defaultMaintenanceBranches detect maintenance branches like "release/1.4.x" and "release/v1.x".
+var defaultMaintenanceBranches = []*regexp.Regexp{
+	regexp.MustCompile(`^release/v?(?P<major>\d+)(?:\.(?P<minor>\d+))?\.x$`),
+}
+
+// maintenanceLine is the release line of a maintenance branch. A branch for 1.4.x only allows patch releases, and a
+// branch for 1.x allows minor releases.
+type maintenanceLine struct {
+	branch   string
+	major    uint64
+	minor    uint64
+	hasMinor bool
+}
+
+// compileMaintenanceBranch compiles a maintenance branch pattern. It must have a "major" named group and may have a
+// "minor" named group.
+func compileMaintenanceBranch(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.SubexpIndex("major") == -1 {
+		return nil, fmt.Errorf("%q has no (?P<major>...) group", pattern)
+	}
+	return re, nil
+}
+
+// findMaintenanceLine returns the release line of ref when it is a maintenance branch matching one of patterns. nil
+// patterns use defaultMaintenanceBranches. It returns nil when ref isn't a maintenance branch.
+func findMaintenanceLine(ref string, patterns []*regexp.Regexp) *maintenanceLine {
+	if patterns == nil {
+		patterns = defaultMaintenanceBranches
+	}
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	for _, re := range patterns {
+		match := re.FindStringSubmatch(branch)
+		if match == nil {
+			continue
+		}
+		major, err := strconv.ParseUint(match[re.SubexpIndex("major")], 10, 64)
+		if err != nil {
+			continue
+		}
+		line := &maintenanceLine{branch: branch, major: major}
+		if idx := re.SubexpIndex("minor"); idx != -1 && match[idx] != "" {
+			line.minor, err = strconv.ParseUint(match[idx], 10, 64)
+			if err != nil {
+				continue
+			}
+			line.hasMinor = true
+		}
+		return line
+	}
+	return nil
+}
+
+func (l *maintenanceLine) String() string {
+	if l.hasMinor {
+		return fmt.Sprintf("%d.%d.x", l.major, l.minor)
+	}
+	return fmt.Sprintf("%d.x", l.major)
+}
+
+// maxLevel is the highest change level allowed on the branch.
+func (l *maintenanceLine) maxLevel() changeLevel {
+	if l.hasMinor {
+		return changeLevelPatch
+	}
+	return changeLevelMinor
+}
+
+// contains returns true when v is in the release line.
+func (l *maintenanceLine) contains(v *semver.Version) bool {
+	if v.Major() != l.major {
+		return false
+	}
+	return !l.hasMinor || v.Minor() == l.minor
+}
+
+// latestTag returns the tag of the highest non-prerelease version in the release line.
+func (l *maintenanceLine) latestTag(tags []string) (string, error) {
+	tag, _ := latestTag(tags, "", semverScheme{}, func(v schemeVersion) bool {
+		sv, ok := asSemver(v)
+		return ok && l.contains(sv)
+	})
+	if tag == "" {
+		return "", fmt.Errorf("no tags found for the %s release line of maintenance branch %s", l, l.branch)
+	}
+	return tag, nil
+}
+
+// checkPrevious returns an error when prev isn't in the release line.
+func (l *maintenanceLine) checkPrevious(prev *semver.Version) error {
+	if l.contains(prev) {
+		return nil
+	}
+	return fmt.Errorf("previous version %s is not in the %s release line of maintenance branch %s", prev, l, l.branch)
+}
+
+// levelProblem describes result.ChangeLevel exceeding what the branch allows. It returns "" when the level is
+// allowed.
+func (l *maintenanceLine) levelProblem(result *Result) string {
+	if result.ChangeLevel <= l.maxLevel() {
+		return ""
+	}
+	var pulls []string
+	seen := map[int]bool{}
+	for _, c := range result.Commits {
+		for _, p := range c.Pulls {
+			if seen[p.Number] || p.ChangeLevel <= l.maxLevel() {
+				continue
+			}
+			seen[p.Number] = true
+			pulls = append(pulls, fmt.Sprintf("#%d (%s)", p.Number, p.ChangeLevel))
+		}
+	}
+	sort.Strings(pulls)
+	msg := fmt.Sprintf(
+		"%s is a maintenance branch for %s which only allows %s changes, but the release is a %s change",
+		l.branch, l, l.maxLevel(), result.ChangeLevel,
+	)
+	if len(pulls) > 0 {
+		msg += ": " + strings.Join(pulls, ", ")
+	}
+	return msg
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findMaintenanceLine(t *testing.T) {
+	for _, td := range []struct {
+		ref      string
+		patterns []*regexp.Regexp
+		want     string
+		maxLevel changeLevel
+	}{
+		{ref: "release/1.4.x", want: "1.4.x", maxLevel: changeLevelPatch},
+		{ref: "refs/heads/release/v2.x", want: "2.x", maxLevel: changeLevelMinor},
+		{ref: "main"},
+		{ref: "release/1.4"},
+		{ref: "feature/release/1.x"},
+		{
+			ref:      "maint-3",
+			patterns: []*regexp.Regexp{regexp.MustCompile(`^maint-(?P<major>\d+)$`)},
+			want:     "3.x",
+			maxLevel: changeLevelMinor,
+		},
+		{
+			ref:      "release/1.4.x",
+			patterns: []*regexp.Regexp{regexp.MustCompile(`^maint-(?P<major>\d+)$`)},
+		},
+	} {
+		t.Run(td.ref, func(t *testing.T) {
+			got := findMaintenanceLine(td.ref, td.patterns)
+			if td.want == "" {
+				require.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			require.Equal(t, td.want, got.String())
+			require.Equal(t, td.maxLevel, got.maxLevel())
+		})
+	}
+
+	line := findMaintenanceLine("release/1.4.x", nil)
+	require.True(t, line.contains(semver.MustParse("1.4.7")))
+	require.False(t, line.contains(semver.MustParse("1.5.0")))
+	require.False(t, line.contains(semver.MustParse("2.4.0")))
+}
+
+func Test_compileMaintenanceBranch(t *testing.T) {
+	_, err := compileMaintenanceBranch(`^maint-(\d+)$`)
+	require.EqualError(t, err, `"^maint-(\\d+)$" has no (?P<major>...) group`)
+	_, err = compileMaintenanceBranch(`^maint-(?P<major>\d+$`)
+	require.Error(t, err)
+}
+
+func Test_next_maintenanceBranch(t *testing.T) {
+	ctx := context.Background()
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	tags := []string{"v1.4.2", "v1.4.3", "v1.5.0", "v2.0.0", "v1.4.4-rc1"}
+
+	t.Run("patch", func(t *testing.T) {
+		got, err := next(ctx, nextOptions{
+			gh:   labeledPullStub(t, sha1, "bug").withTags(tags...),
+			repo: "willabides/semver-next",
+			head: "release/1.4.x",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.4.3", got.PreviousVersion)
+		require.Equal(t, "1.4.4", got.NextVersion)
+		require.Equal(t, "1.4.x", got.MaintenanceLine)
+	})
+
+	t.Run("minor", func(t *testing.T) {
+		_, err := next(ctx, nextOptions{
+			gh:   labeledPullStub(t, sha1, "enhancement").withTags(tags...),
+			repo: "willabides/semver-next",
+			head: "release/1.4.x",
+		})
+		require.EqualError(t, err, "release/1.4.x is a maintenance branch for 1.4.x which only allows patch changes, but the release is a minor change: #1 (minor)")
+	})
+
+	t.Run("previous version outside the line", func(t *testing.T) {
+		_, err := next(ctx, nextOptions{
+			gh:   labeledPullStub(t, sha1, "bug").withTags(tags...),
+			repo: "willabides/semver-next",
+			base: "v1.5.0",
+			head: "release/1.4.x",
+		})
+		require.EqualError(t, err, "previous version 1.5.0 is not in the 1.4.x release line of maintenance branch release/1.4.x")
+	})
+}
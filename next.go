@@ -4,10 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
-
-	"github.com/Masterminds/semver/v3"
 )
 
 type changeLevel int
@@ -80,59 +79,97 @@ type Result struct {
 	PreviousVersion string         `json:"previous_version"`
 	ChangeLevel     changeLevel    `json:"change_level"`
 	Commits         []ResultCommit `json:"commits,omitempty"`
+
+	// ComputedChangeLevel is the change level before --min-bump and --max-bump were applied.
+	ComputedChangeLevel changeLevel `json:"computed_change_level"`
+
+	// AppliedChangeLevel is the change level used for NextVersion. It is always the same as ChangeLevel.
+	AppliedChangeLevel changeLevel `json:"applied_change_level"`
+
+	// ClampedBy is clampedByMinBump or clampedByMaxBump when AppliedChangeLevel differs from ComputedChangeLevel.
+	ClampedBy string `json:"clamped_by,omitempty"`
+
+	// CompareStatus is how the head ref relates to the base ref. One of compareStatusAhead or
+	// compareStatusIdentical; refs that are behind or diverged are an error.
+	CompareStatus string `json:"compare_status,omitempty"`
+
+	// ExistingTags are tags for versions that were skipped because they already exist.
+	ExistingTags []string `json:"existing_tags,omitempty"`
+
+	// MaintenanceLine is the release line when the head ref is a maintenance branch. e.g. "1.4.x"
+	MaintenanceLine string `json:"maintenance_line,omitempty"`
+
+	// Overrides are the labels that forced or capped ChangeLevel.
+	Overrides []ResultOverride `json:"overrides,omitempty"`
+
+	// APIChanges are the changes to exported Go APIs when the API diff is enabled.
+	APIChanges []ResultAPIChange `json:"api_changes,omitempty"`
+
+	// GoModule is set when the Go module path is checked against the next version.
+	GoModule *ResultGoModule `json:"go_module,omitempty"`
+
+	// Warnings are problems that didn't prevent determining the next version.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type ResultCommit struct {
 	Sha         string       `json:"sha"`
 	ChangeLevel changeLevel  `json:"change_level"`
 	Pulls       []ResultPull `json:"pulls,omitempty"`
+
+	// CherryPickedFrom is the sha from the commit message's "(cherry picked from commit ...)" trailer.
+	CherryPickedFrom string `json:"cherry_picked_from,omitempty"`
 }
 
 type ResultPull struct {
 	Number      int         `json:"number"`
+	Title       string      `json:"title,omitempty"`
 	Labels      []string    `json:"labels,omitempty"`
 	ChangeLevel changeLevel `json:"change_level"`
+
+	// BackportOf is the number of the pull request this one backports.
+	BackportOf int `json:"backport_of,omitempty"`
+
+	// OverrideLabels are labels like "semver:force-major" that override the change level of the release.
+	OverrideLabels []string `json:"override_labels,omitempty"`
+
+	// TitleChangeLevel is the change level of a conventional commit title. It is only set when titles are used.
+	TitleChangeLevel *changeLevel `json:"title_change_level,omitempty"`
+
+	// BodyChangeLevel is the highest change level found in the body. It is only set when body rules are configured.
+	BodyChangeLevel *changeLevel `json:"body_change_level,omitempty"`
+
+	// ReleaseNote is the text of release note blocks in the body.
+	ReleaseNote string `json:"release_note,omitempty"`
+
+	Body string `json:"-"`
 }
 
-func getCommitPRs(ctx context.Context, gh wrapper, owner, repo, commitSha string) ([]ResultPull, error) {
-	result, err := gh.ListPullRequestsWithCommit(ctx, owner, repo, commitSha)
-	if err != nil {
-		return nil, err
-	}
-	for i := range result {
-		filteredLabels := make([]string, 0, len(result[i].Labels))
-		for _, l := range result[i].Labels {
-			l = strings.ToLower(l)
-			level, ok := labelLevels[l]
-			if !ok {
-				continue
-			}
-			filteredLabels = append(filteredLabels, l)
-			if level > result[i].ChangeLevel {
-				result[i].ChangeLevel = level
-			}
-		}
-		result[i].Labels = filteredLabels
-	}
-	return result, nil
+// hasLevel returns true when the pull request has a recognized label, override label, title or body.
+func (p *ResultPull) hasLevel() bool {
+	return len(p.Labels) > 0 || len(p.OverrideLabels) > 0 || p.TitleChangeLevel != nil || p.BodyChangeLevel != nil
 }
 
-func compareCommits(ctx context.Context, gh wrapper, owner, repo, baseRef, headRef string) ([]ResultCommit, error) {
-	var result []ResultCommit
-	commitShas, err := gh.CompareCommits(ctx, owner, repo, baseRef, headRef)
-	if err != nil {
-		return nil, err
-	}
-	result = make([]ResultCommit, len(commitShas))
+// labelLevelFunc returns the change level for a lowercase label and whether the label is recognized.
+type labelLevelFunc func(label string) (changeLevel, bool)
+
+func defaultLabelLevel(label string) (changeLevel, bool) {
+	level, ok := labelLevels[label]
+	return level, ok
+}
+
+// fetchCommitPulls gets the merged pull requests for each commit sha. The result is keyed by sha.
+func fetchCommitPulls(ctx context.Context, gh wrapper, owner, repo string, commitShas []string) (map[string][]ResultPull, error) {
+	pulls := make([][]ResultPull, len(commitShas))
+	var err error
 	var wg sync.WaitGroup
 	var errLock sync.Mutex
 	for i := range commitShas {
 		commitSha := commitShas[i]
-		result[i] = ResultCommit{Sha: commitSha}
 		wg.Add(1)
 		go func(idx int) {
 			var e error
-			result[idx].Pulls, e = getCommitPRs(ctx, gh, owner, repo, commitSha)
+			pulls[idx], e = gh.ListPullRequestsWithCommit(ctx, owner, repo, commitSha)
 			errLock.Lock()
 			err = errors.Join(err, e)
 			errLock.Unlock()
@@ -143,33 +180,123 @@ func compareCommits(ctx context.Context, gh wrapper, owner, repo, baseRef, headR
 	if err != nil {
 		return nil, err
 	}
-	var commitsMissingLabels []ResultCommit
-	for i := range result {
-		hasLabel := false
+	result := make(map[string][]ResultPull, len(commitShas))
+	for i, sha := range commitShas {
+		result[sha] = pulls[i]
+	}
+	return result, nil
+}
+
+// fetchCommits fetches the pull requests of commits, resolves backports and evaluates them with rules.
+func fetchCommits(ctx context.Context, gh wrapper, owner, repo string, commits []repoCommit, rules *pullRules) ([]ResultCommit, error) {
+	shas := commitShas(commits)
+	commitPulls, err := fetchCommitPulls(ctx, gh, owner, repo, shas)
+	if err != nil {
+		return nil, err
+	}
+	cherryPicks, err := resolveBackports(ctx, gh, owner, repo, commits, commitPulls)
+	if err != nil {
+		return nil, err
+	}
+	return withCherryPicks(evaluateCommits(shas, commitPulls, rules), cherryPicks), nil
+}
+
+// withCherryPicks sets CherryPickedFrom on commits from cherryPicks and returns commits.
+func withCherryPicks(commits []ResultCommit, cherryPicks map[string]string) []ResultCommit {
+	for i := range commits {
+		commits[i].CherryPickedFrom = cherryPicks[commits[i].Sha]
+	}
+	return commits
+}
+
+// evaluateCommits builds a ResultCommit for each sha using rules to evaluate its pull requests.
+func evaluateCommits(commitShas []string, commitPulls map[string][]ResultPull, rules *pullRules) []ResultCommit {
+	result := make([]ResultCommit, len(commitShas))
+	for i, sha := range commitShas {
+		result[i] = ResultCommit{
+			Sha:   sha,
+			Pulls: rules.evaluate(commitPulls[sha]),
+		}
 		for _, p := range result[i].Pulls {
-			if len(p.Labels) > 0 {
-				hasLabel = true
-			}
 			if p.ChangeLevel > result[i].ChangeLevel {
 				result[i].ChangeLevel = p.ChangeLevel
 			}
 		}
-		if len(result[i].Pulls) > 0 && !hasLabel {
-			commitsMissingLabels = append(commitsMissingLabels, result[i])
-		}
 	}
-	if len(commitsMissingLabels) > 0 {
-		var commitMsgs []string
-		for _, c := range commitsMissingLabels {
-			var prNumbers []string
-			for _, p := range c.Pulls {
-				prNumbers = append(prNumbers, fmt.Sprintf("#%d", p.Number))
+	return result
+}
+
+// checkMissingLabels returns an error listing commits whose pull requests have no recognized labels.
+func checkMissingLabels(commits []ResultCommit) error {
+	var commitsMissingLabels []ResultCommit
+	for _, c := range commits {
+		hasLabel := false
+		for _, p := range c.Pulls {
+			if p.hasLevel() {
+				hasLabel = true
 			}
-			commitMsgs = append(commitMsgs, fmt.Sprintf("%s (%s)", c.Sha, strings.Join(prNumbers, ", ")))
 		}
-		return nil, fmt.Errorf("commits with no semver labels on associated PRs:\n%s", strings.Join(commitMsgs, "\n"))
+		if len(c.Pulls) > 0 && !hasLabel {
+			commitsMissingLabels = append(commitsMissingLabels, c)
+		}
 	}
-	return result, nil
+	if len(commitsMissingLabels) == 0 {
+		return nil
+	}
+	var commitMsgs []string
+	for _, c := range commitsMissingLabels {
+		var prNumbers []string
+		for _, p := range c.Pulls {
+			prNumbers = append(prNumbers, fmt.Sprintf("#%d", p.Number))
+		}
+		commitMsgs = append(commitMsgs, fmt.Sprintf("%s (%s)", c.Sha, strings.Join(prNumbers, ", ")))
+	}
+	return fmt.Errorf("commits with no semver labels on associated PRs:\n%s", strings.Join(commitMsgs, "\n"))
+}
+
+// Values of comparison.Status and Result.CompareStatus.
+const (
+	compareStatusAhead     = "ahead"
+	compareStatusBehind    = "behind"
+	compareStatusDiverged  = "diverged"
+	compareStatusIdentical = "identical"
+)
+
+// checkCompareStatus returns an error unless headRef descends from baseRef. A bump computed against a base that
+// isn't an ancestor of head would count the wrong commits.
+func checkCompareStatus(baseRef, headRef, status string) error {
+	switch status {
+	case compareStatusBehind:
+		return fmt.Errorf("%s is behind %s; the head ref must descend from the base ref", headRef, baseRef)
+	case compareStatusDiverged:
+		return fmt.Errorf("%s has diverged from %s; the head ref must descend from the base ref", headRef, baseRef)
+	}
+	return nil
+}
+
+// compareCommits returns the commits between baseRef and headRef along with the comparison status. comp is the
+// comparison of baseRef and headRef when it has already been fetched. nil fetches it.
+func compareCommits(ctx context.Context, gh wrapper, owner, repo, baseRef, headRef string, comp *comparison, rules *pullRules) ([]ResultCommit, string, error) {
+	var err error
+	if comp == nil {
+		comp, err = gh.CompareCommits(ctx, owner, repo, baseRef, headRef)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	err = checkCompareStatus(baseRef, headRef, comp.Status)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := fetchCommits(ctx, gh, owner, repo, comp.Commits, rules)
+	if err != nil {
+		return nil, "", err
+	}
+	err = checkMissingLabels(result)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, comp.Status, nil
 }
 
 type nextOptions struct {
@@ -180,69 +307,226 @@ type nextOptions struct {
 	head        string
 	minBump     string
 	maxBump     string
+	goModule    string
+	goAPIDiff   bool
+	checkout    string
+
+	// conflictingLabels is how pull requests with labels for different change levels are handled. One of
+	// conflictingLabelsMax, conflictingLabelsWarn or conflictingLabelsError. Empty means conflictingLabelsMax.
+	conflictingLabels string
+
+	// maintenanceBranches detect maintenance branches from head. nil uses defaultMaintenanceBranches.
+	maintenanceBranches []*regexp.Regexp
+
+	// autoSkip skips versions that already have tags instead of returning an error.
+	autoSkip bool
+
+	// ignoreExisting skips checking whether the next version already has a tag. history uses it to recompute
+	// versions that were already released.
+	ignoreExisting bool
+
+	// strict makes a previous version that doesn't match base an error instead of a warning.
+	strict bool
+
+	// tags are the repository's tags when they have already been listed. nil lists them.
+	tags []string
+
+	// comparison is the comparison of base and head when it has already been fetched. nil fetches it.
+	comparison *comparison
+
+	// scheme parses and bumps versions. nil uses semverScheme. Maintenance branches are only detected with
+	// semverScheme.
+	scheme versionScheme
+
+	rules *pullRules
 }
 
-func next(ctx context.Context, opts nextOptions) (*Result, error) {
-	minBump := opts.minBump
+// bumpLimits parses minBump and maxBump, applying defaults for empty values.
+func bumpLimits(minBump, maxBump string) (minLevel, maxLevel changeLevel, _ error) {
 	if minBump == "" {
 		minBump = "no change"
 	}
-	maxBump := opts.maxBump
 	if maxBump == "" {
 		maxBump = "major"
 	}
-	minBumpLevel, err := parseChangeLevel(minBump)
+	minLevel, err := parseChangeLevel(minBump)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxLevel, err = parseChangeLevel(maxBump)
+	if err != nil {
+		return 0, 0, err
+	}
+	if minLevel > maxLevel {
+		return 0, 0, fmt.Errorf("minBump must be less than or equal to maxBump")
+	}
+	return minLevel, maxLevel, nil
+}
+
+func splitRepo(repo string) (owner, name string, _ error) {
+	repoParts := strings.Split(repo, "/")
+	if len(repoParts) != 2 {
+		return "", "", fmt.Errorf("repo must be in the form owner/name")
+	}
+	return repoParts[0], repoParts[1], nil
+}
+
+// commitsChangeLevel returns the highest change level of commits.
+func commitsChangeLevel(commits []ResultCommit) changeLevel {
+	level := changeLevelNoChange
+	for _, c := range commits {
+		if c.ChangeLevel > level {
+			level = c.ChangeLevel
+		}
+	}
+	return level
+}
+
+// newResult creates a Result with the highest change level of commits.
+func newResult(prev schemeVersion, commits []ResultCommit) *Result {
+	return &Result{
+		Commits:         commits,
+		PreviousVersion: prev.String(),
+		ChangeLevel:     commitsChangeLevel(commits),
+	}
+}
+
+// Values of Result.ClampedBy.
+const (
+	clampedByMinBump = "min_bump"
+	clampedByMaxBump = "max_bump"
+)
+
+// applyBump clamps result.ChangeLevel to minLevel and maxLevel and sets result.NextVersion. The level before and
+// after clamping are recorded in result.ComputedChangeLevel and result.AppliedChangeLevel.
+func applyBump(result *Result, prev schemeVersion, minLevel, maxLevel changeLevel) {
+	result.ComputedChangeLevel = result.ChangeLevel
+	result.ClampedBy = ""
+	if result.ChangeLevel < minLevel && len(result.Commits) > 0 {
+		result.ChangeLevel = minLevel
+		result.ClampedBy = clampedByMinBump
+	}
+	if result.ChangeLevel > maxLevel {
+		result.ChangeLevel = maxLevel
+		result.ClampedBy = clampedByMaxBump
+	}
+	result.AppliedChangeLevel = result.ChangeLevel
+	result.NextVersion = prev.bump(result.ChangeLevel).String()
+}
+
+// applyAPIDiff raises result.ChangeLevel to the level of the API changes between base and head in the local
+// checkout.
+func applyAPIDiff(ctx context.Context, opts *nextOptions, subdir, base string, result *Result) error {
+	if !opts.goAPIDiff {
+		return nil
+	}
+	changes, err := goAPIDiff(ctx, opts.checkout, subdir, base, opts.head)
+	if err != nil {
+		return err
+	}
+	result.APIChanges = changes
+	level := apiChangeLevel(changes)
+	if level > result.ChangeLevel {
+		result.ChangeLevel = level
+	}
+	return nil
+}
+
+func next(ctx context.Context, opts nextOptions) (*Result, error) {
+	minBumpLevel, maxBumpLevel, err := bumpLimits(opts.minBump, opts.maxBump)
 	if err != nil {
 		return nil, err
 	}
-	maxBumpLevel, err := parseChangeLevel(maxBump)
+	scheme := opts.scheme
+	if scheme == nil {
+		scheme = semverScheme{}
+	}
+	_, isSemver := scheme.(semverScheme)
+	if !isSemver && opts.goModule != "" && opts.goModule != goModuleCheckOff {
+		return nil, fmt.Errorf("checking the go module requires the semver version scheme")
+	}
+	var line *maintenanceLine
+	if isSemver {
+		line = findMaintenanceLine(opts.head, opts.maintenanceBranches)
+	}
+	if line != nil && maxBumpLevel > line.maxLevel() {
+		maxBumpLevel = line.maxLevel()
+		if minBumpLevel > maxBumpLevel {
+			return nil, fmt.Errorf("minBump %s is not allowed on maintenance branch %s", minBumpLevel, line.branch)
+		}
+	}
+	owner, repo, err := splitRepo(opts.repo)
 	if err != nil {
 		return nil, err
 	}
+	tags := opts.tags
+	if tags == nil {
+		tags, err = opts.gh.ListTags(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	base := opts.base
+	if base == "" && line != nil {
+		base, err = line.latestTag(tags)
+		if err != nil {
+			return nil, err
+		}
+	}
 	prevVersion := opts.prevVersion
 	if prevVersion == "" {
-		prevVersion = opts.base
-	}
-	if minBumpLevel > maxBumpLevel {
-		return nil, fmt.Errorf("minBump must be less than or equal to maxBump")
+		prevVersion = base
 	}
-	prev, err := semver.NewVersion(prevVersion)
+	prev, err := scheme.parse(prevVersion)
 	if err != nil {
 		return nil, fmt.Errorf("invalid previous version %q: %v", prevVersion, err)
 	}
-	repoParts := strings.Split(opts.repo, "/")
-	if len(repoParts) != 2 {
-		return nil, fmt.Errorf("repo must be in the form owner/name")
+	if line != nil {
+		prevSemver, _ := asSemver(prev)
+		err = line.checkPrevious(prevSemver)
+		if err != nil {
+			return nil, err
+		}
 	}
-	owner, repo := repoParts[0], repoParts[1]
-	resultCommits, err := compareCommits(ctx, opts.gh, owner, repo, opts.base, opts.head)
+	resultCommits, status, err := compareCommits(ctx, opts.gh, owner, repo, base, opts.head, opts.comparison, opts.rules)
 	if err != nil {
 		return nil, err
 	}
-	result := Result{
-		Commits:         resultCommits,
-		PreviousVersion: prev.String(),
+	result := newResult(prev, resultCommits)
+	result.CompareStatus = status
+	if line != nil {
+		result.MaintenanceLine = line.String()
 	}
-	for _, c := range resultCommits {
-		if c.ChangeLevel > result.ChangeLevel {
-			result.ChangeLevel = c.ChangeLevel
+	if opts.prevVersion != "" {
+		err = checkPrevVersion(ctx, opts.gh, owner, repo, base, tags, scheme, prev, opts.strict, result)
+		if err != nil {
+			return nil, err
 		}
 	}
-	if result.ChangeLevel < minBumpLevel && len(result.Commits) > 0 {
-		result.ChangeLevel = minBumpLevel
+	err = checkConflictingLabels(opts.conflictingLabels, opts.rules, result)
+	if err != nil {
+		return nil, err
 	}
-	if result.ChangeLevel > maxBumpLevel {
-		result.ChangeLevel = maxBumpLevel
+	err = applyAPIDiff(ctx, &opts, "", base, result)
+	if err != nil {
+		return nil, err
 	}
-	switch result.ChangeLevel {
-	case changeLevelNoChange:
-		result.NextVersion = prev.String()
-	case changeLevelPatch:
-		result.NextVersion = prev.IncPatch().String()
-	case changeLevelMinor:
-		result.NextVersion = prev.IncMinor().String()
-	case changeLevelMajor:
-		result.NextVersion = prev.IncMajor().String()
+	applyOverrides(result)
+	if line != nil {
+		if problem := line.levelProblem(result); problem != "" {
+			return nil, fmt.Errorf("%s", problem)
+		}
+	}
+	applyBump(result, prev, minBumpLevel, maxBumpLevel)
+	if !opts.ignoreExisting {
+		err = checkExistingVersion(tags, "", scheme, opts.autoSkip, result)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return &result, nil
+	err = checkGoModule(ctx, opts.gh, owner, repo, opts.head, "", opts.goModule, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
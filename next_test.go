@@ -7,7 +7,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,14 +17,116 @@ import (
 type wrapperStub struct {
 	listPullRequestsWithCommit func(ctx context.Context, owner, repo, sha string) ([]ResultPull, error)
 	compareCommits             func(ctx context.Context, owner, repo, base, head string) ([]string, error)
+	listTags                   func(ctx context.Context, owner, repo string) ([]string, error)
+	getFileContent             func(ctx context.Context, owner, repo, path, ref string) ([]byte, error)
+	getCommitSha               func(ctx context.Context, owner, repo, ref string) (string, error)
+	getCommitDate              func(ctx context.Context, owner, repo, ref string) (time.Time, error)
+	listCommitFiles            func(ctx context.Context, owner, repo, sha string) ([]string, error)
+	getPullRequest             func(ctx context.Context, owner, repo string, number int) (*pullRequest, error)
+	listIssueComments          func(ctx context.Context, owner, repo string, number int) ([]issueComment, error)
+	createIssueComment         func(ctx context.Context, owner, repo string, number int, body string) error
+	editIssueComment           func(ctx context.Context, owner, repo string, id int64, body string) error
+	createCheckRun             func(ctx context.Context, owner, repo string, run *checkRun) error
+	listLabels                 func(ctx context.Context, owner, repo string) ([]repoLabel, error)
+	createLabel                func(ctx context.Context, owner, repo string, label repoLabel) error
+	editLabel                  func(ctx context.Context, owner, repo, name string, label repoLabel) error
+	deleteLabel                func(ctx context.Context, owner, repo, name string) error
+
+	// commitMessages are the messages of commits returned by compareCommits keyed by sha.
+	commitMessages map[string]string
+
+	// compareStatus is the status returned by CompareCommits. Empty means compareStatusAhead.
+	compareStatus string
+
+	// compareStatuses override compareStatus for comparisons keyed by "<base>...<head>".
+	compareStatuses map[string]string
 }
 
 func (w *wrapperStub) ListPullRequestsWithCommit(ctx context.Context, owner, repo, sha string) ([]ResultPull, error) {
 	return w.listPullRequestsWithCommit(ctx, owner, repo, sha)
 }
 
-func (w *wrapperStub) CompareCommits(ctx context.Context, owner, repo, base, head string) ([]string, error) {
-	return w.compareCommits(ctx, owner, repo, base, head)
+func (w *wrapperStub) CompareCommits(ctx context.Context, owner, repo, base, head string) (*comparison, error) {
+	shas, err := w.compareCommits(ctx, owner, repo, base, head)
+	if err != nil {
+		return nil, err
+	}
+	comp := comparison{
+		Status:  w.compareStatus,
+		Commits: make([]repoCommit, len(shas)),
+	}
+	if status, ok := w.compareStatuses[base+"..."+head]; ok {
+		comp.Status = status
+	}
+	if comp.Status == "" {
+		comp.Status = compareStatusAhead
+	}
+	for i, sha := range shas {
+		comp.Commits[i] = repoCommit{Sha: sha, Message: w.commitMessages[sha]}
+	}
+	return &comp, nil
+}
+
+func (w *wrapperStub) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	if w.listTags == nil {
+		return nil, nil
+	}
+	return w.listTags(ctx, owner, repo)
+}
+
+func (w *wrapperStub) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	return w.getFileContent(ctx, owner, repo, path, ref)
+}
+
+func (w *wrapperStub) GetCommitSha(ctx context.Context, owner, repo, ref string) (string, error) {
+	return w.getCommitSha(ctx, owner, repo, ref)
+}
+
+func (w *wrapperStub) GetCommitDate(ctx context.Context, owner, repo, ref string) (time.Time, error) {
+	return w.getCommitDate(ctx, owner, repo, ref)
+}
+
+func (w *wrapperStub) ListCommitFiles(ctx context.Context, owner, repo, sha string) ([]string, error) {
+	if w.listCommitFiles == nil {
+		return nil, nil
+	}
+	return w.listCommitFiles(ctx, owner, repo, sha)
+}
+
+func (w *wrapperStub) GetPullRequest(ctx context.Context, owner, repo string, number int) (*pullRequest, error) {
+	return w.getPullRequest(ctx, owner, repo, number)
+}
+
+func (w *wrapperStub) ListIssueComments(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+	return w.listIssueComments(ctx, owner, repo, number)
+}
+
+func (w *wrapperStub) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error {
+	return w.createIssueComment(ctx, owner, repo, number, body)
+}
+
+func (w *wrapperStub) EditIssueComment(ctx context.Context, owner, repo string, id int64, body string) error {
+	return w.editIssueComment(ctx, owner, repo, id, body)
+}
+
+func (w *wrapperStub) CreateCheckRun(ctx context.Context, owner, repo string, run *checkRun) error {
+	return w.createCheckRun(ctx, owner, repo, run)
+}
+
+func (w *wrapperStub) ListLabels(ctx context.Context, owner, repo string) ([]repoLabel, error) {
+	return w.listLabels(ctx, owner, repo)
+}
+
+func (w *wrapperStub) CreateLabel(ctx context.Context, owner, repo string, label repoLabel) error {
+	return w.createLabel(ctx, owner, repo, label)
+}
+
+func (w *wrapperStub) EditLabel(ctx context.Context, owner, repo, name string, label repoLabel) error {
+	return w.editLabel(ctx, owner, repo, name, label)
+}
+
+func (w *wrapperStub) DeleteLabel(ctx context.Context, owner, repo, name string) error {
+	return w.deleteLabel(ctx, owner, repo, name)
 }
 
 type listPullRequestsWithCommitCall struct {
@@ -51,6 +155,36 @@ func mockListPullRequestsWithCommit(t *testing.T, calls []listPullRequestsWithCo
 	}
 }
 
+// labeledPullStub returns a wrapperStub where every comparison has the single commit sha, which is in pull request #1
+// labeled with labels.
+func labeledPullStub(t *testing.T, sha string, labels ...string) *wrapperStub {
+	return &wrapperStub{
+		compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+			return []string{sha}, nil
+		},
+		listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+			{owner: "willabides", repo: "semver-next", sha: sha, result: []ResultPull{{Number: 1, Labels: labels}}},
+		}),
+	}
+}
+
+// withTags makes ListTags return tags.
+func (w *wrapperStub) withTags(tags ...string) *wrapperStub {
+	w.listTags = func(ctx context.Context, owner, repo string) ([]string, error) {
+		return tags, nil
+	}
+	return w
+}
+
+// withPullRequest makes GetPullRequest return pr when it is called with pr's number.
+func (w *wrapperStub) withPullRequest(t *testing.T, pr pullRequest) *wrapperStub {
+	w.getPullRequest = func(ctx context.Context, owner, repo string, number int) (*pullRequest, error) {
+		assert.Equal(t, pr.Number, number)
+		return &pr, nil
+	}
+	return w
+}
+
 func Test_next(t *testing.T) {
 	ctx := context.Background()
 
@@ -95,9 +229,12 @@ func Test_next(t *testing.T) {
 		)
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "1.0.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelMajor,
+			NextVersion:         "1.0.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelMajor,
+			ComputedChangeLevel: changeLevelMajor,
+			AppliedChangeLevel:  changeLevelMajor,
+			CompareStatus:       compareStatusAhead,
 			Commits: []ResultCommit{
 				{
 					Sha: sha1,
@@ -153,9 +290,12 @@ func Test_next(t *testing.T) {
 		)
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.16.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelMinor,
+			NextVersion:         "0.16.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelMinor,
+			ComputedChangeLevel: changeLevelMinor,
+			AppliedChangeLevel:  changeLevelMinor,
+			CompareStatus:       compareStatusAhead,
 			Commits: []ResultCommit{
 				{
 					Sha: sha1,
@@ -211,9 +351,12 @@ func Test_next(t *testing.T) {
 		)
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.15.1",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelPatch,
+			NextVersion:         "0.15.1",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelPatch,
+			ComputedChangeLevel: changeLevelPatch,
+			AppliedChangeLevel:  changeLevelPatch,
+			CompareStatus:       compareStatusAhead,
 			Commits: []ResultCommit{
 				{
 					Sha: sha1,
@@ -269,9 +412,12 @@ func Test_next(t *testing.T) {
 		)
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.15.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelNoChange,
+			NextVersion:         "0.15.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelNoChange,
+			ComputedChangeLevel: changeLevelNoChange,
+			AppliedChangeLevel:  changeLevelNoChange,
+			CompareStatus:       compareStatusAhead,
 			Commits: []ResultCommit{
 				{
 					Sha: sha1,
@@ -326,6 +472,51 @@ func Test_next(t *testing.T) {
 		require.Contains(t, err.Error(), fmt.Sprintf("%s (#2, #3)", sha2))
 	})
 
+	t.Run("conflicting labels", func(t *testing.T) {
+		gh := func() *wrapperStub {
+			return &wrapperStub{
+				compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+					return []string{sha1}, nil
+				},
+				listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+					{
+						owner: "willabides", repo: "semver-next", sha: sha1,
+						result: []ResultPull{
+							{Number: 1, Labels: []string{"bug", "breaking"}},
+							{Number: 2, Labels: []string{"patch", "bug"}},
+						},
+					},
+				}),
+			}
+		}
+		opts := nextOptions{
+			repo: "willabides/semver-next",
+			base: "v0.15.0",
+			head: sha1,
+		}
+
+		opts.gh = gh()
+		got, err := next(ctx, opts)
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", got.NextVersion)
+		require.Empty(t, got.Warnings)
+
+		opts.gh = gh()
+		opts.conflictingLabels = conflictingLabelsWarn
+		got, err = next(ctx, opts)
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", got.NextVersion)
+		require.Equal(t, []string{
+			"pull request #1 has labels for different change levels: breaking (major), bug (patch)",
+		}, got.Warnings)
+
+		opts.gh = gh()
+		opts.conflictingLabels = conflictingLabelsError
+		_, err = next(ctx, opts)
+		require.EqualError(t, err, "pull requests with conflicting semver labels:\n"+
+			"pull request #1 has labels for different change levels: breaking (major), bug (patch)")
+	})
+
 	t.Run("empty diff", func(t *testing.T) {
 		gh := wrapperStub{
 			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
@@ -334,6 +525,7 @@ func Test_next(t *testing.T) {
 				assert.Equal(t, sha1, head)
 				return []string{}, nil
 			},
+			compareStatus: compareStatusIdentical,
 		}
 		got, err := next(ctx, nextOptions{
 			repo: "willabides/semver-next",
@@ -343,10 +535,13 @@ func Test_next(t *testing.T) {
 		})
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.15.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelNoChange,
-			Commits:         []ResultCommit{},
+			NextVersion:         "0.15.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelNoChange,
+			ComputedChangeLevel: changeLevelNoChange,
+			AppliedChangeLevel:  changeLevelNoChange,
+			CompareStatus:       compareStatusIdentical,
+			Commits:             []ResultCommit{},
 		}
 		require.Equal(t, &want, got)
 	})
@@ -359,6 +554,7 @@ func Test_next(t *testing.T) {
 				assert.Equal(t, sha1, head)
 				return []string{}, nil
 			},
+			compareStatus: compareStatusIdentical,
 		}
 		got, err := next(ctx, nextOptions{
 			repo:    "willabides/semver-next",
@@ -369,10 +565,13 @@ func Test_next(t *testing.T) {
 		})
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.15.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelNoChange,
-			Commits:         []ResultCommit{},
+			NextVersion:         "0.15.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelNoChange,
+			ComputedChangeLevel: changeLevelNoChange,
+			AppliedChangeLevel:  changeLevelNoChange,
+			CompareStatus:       compareStatusIdentical,
+			Commits:             []ResultCommit{},
 		}
 		require.Equal(t, &want, got)
 	})
@@ -413,9 +612,13 @@ func Test_next(t *testing.T) {
 		)
 		require.NoError(t, err)
 		want := Result{
-			NextVersion:     "0.16.0",
-			PreviousVersion: "0.15.0",
-			ChangeLevel:     changeLevelMinor,
+			NextVersion:         "0.16.0",
+			PreviousVersion:     "0.15.0",
+			ChangeLevel:         changeLevelMinor,
+			ComputedChangeLevel: changeLevelPatch,
+			AppliedChangeLevel:  changeLevelMinor,
+			CompareStatus:       compareStatusAhead,
+			ClampedBy:           clampedByMinBump,
 			Commits: []ResultCommit{
 				{
 					Sha: sha1,
@@ -454,6 +657,38 @@ func Test_next(t *testing.T) {
 		require.EqualError(t, err, assert.AnError.Error())
 	})
 
+	t.Run("head behind base", func(t *testing.T) {
+		gh := wrapperStub{
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				return []string{}, nil
+			},
+			compareStatus: compareStatusBehind,
+		}
+		_, err := next(ctx, nextOptions{
+			repo: "willabides/semver-next",
+			base: "v0.15.0",
+			head: "main",
+			gh:   &gh,
+		})
+		require.EqualError(t, err, "main is behind v0.15.0; the head ref must descend from the base ref")
+	})
+
+	t.Run("head diverged from base", func(t *testing.T) {
+		gh := wrapperStub{
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				return []string{sha1}, nil
+			},
+			compareStatus: compareStatusDiverged,
+		}
+		_, err := next(ctx, nextOptions{
+			repo: "willabides/semver-next",
+			base: "v0.15.0",
+			head: "main",
+			gh:   &gh,
+		})
+		require.EqualError(t, err, "main has diverged from v0.15.0; the head ref must descend from the base ref")
+	})
+
 	t.Run("listPullRequestsWithCommit error", func(t *testing.T) {
 		gh := wrapperStub{
 			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
@@ -488,7 +723,7 @@ func Test_next(t *testing.T) {
 	})
 
 	t.Run("prevVersion not valid semver", func(t *testing.T) {
-		_, err := next(ctx, nextOptions{prevVersion: "foo"})
+		_, err := next(ctx, nextOptions{repo: "willabides/semver-next", prevVersion: "foo", gh: &wrapperStub{}})
 		require.EqualError(t, err, `invalid previous version "foo": Invalid Semantic Version`)
 	})
 
@@ -502,3 +737,21 @@ func Test_next(t *testing.T) {
 		require.EqualError(t, err, "minBump must be less than or equal to maxBump")
 	})
 }
+
+func Test_applyBump(t *testing.T) {
+	prev := semverVersion{semver.MustParse("1.2.3")}
+	commits := []ResultCommit{{Sha: "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}}
+
+	result := &Result{ChangeLevel: changeLevelMajor, Commits: commits}
+	applyBump(result, prev, changeLevelNoChange, changeLevelMinor)
+	require.Equal(t, "1.3.0", result.NextVersion)
+	require.Equal(t, changeLevelMajor, result.ComputedChangeLevel)
+	require.Equal(t, changeLevelMinor, result.AppliedChangeLevel)
+	require.Equal(t, clampedByMaxBump, result.ClampedBy)
+
+	result = &Result{ChangeLevel: changeLevelPatch, Commits: commits}
+	applyBump(result, prev, changeLevelNoChange, changeLevelMajor)
+	require.Equal(t, "1.2.4", result.NextVersion)
+	require.Equal(t, changeLevelPatch, result.AppliedChangeLevel)
+	require.Empty(t, result.ClampedBy)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"regexp"
+)
+
+// overrideLabelRegexp matches labels that force or cap the change level of a release. e.g. "semver:force-major" or
+// "semver:cap-minor"
+var overrideLabelRegexp = regexp.MustCompile(`^semver:(?:(force)-(major|minor|patch)|(cap)-(minor|patch|none))$`)
+
+// overrideLabels are all the labels matched by overrideLabelRegexp.
+var overrideLabels = []string{
+	"semver:force-major", "semver:force-minor", "semver:force-patch",
+	"semver:cap-minor", "semver:cap-patch", "semver:cap-none",
+}
+
+const (
+	overrideForce = "force"
+	overrideCap   = "cap"
+)
+
+// ResultOverride is a label that forced or capped the change level of a release.
+type ResultOverride struct {
+	Label  string `json:"label"`
+	Pull   int    `json:"pull"`
+	Commit string `json:"commit"`
+	// ChangeLevel is the release's change level after the override.
+	ChangeLevel changeLevel `json:"change_level"`
+
+	kind  string
+	level changeLevel
+}
+
+// parseOverrideLabel returns the kind and level of a lowercase override label and whether label is one.
+func parseOverrideLabel(label string) (kind string, level changeLevel, ok bool) {
+	match := overrideLabelRegexp.FindStringSubmatch(label)
+	if match == nil {
+		return "", changeLevelNoChange, false
+	}
+	kind, name := match[1], match[2]
+	if kind == "" {
+		kind, name = match[3], match[4]
+	}
+	level, err := parseChangeLevel(name)
+	if err != nil {
+		return "", changeLevelNoChange, false
+	}
+	return kind, level, true
+}
+
+// applyOverrides applies the override labels on result's pull requests to result.ChangeLevel. The highest force
+// label raises the level to its own but never lowers it, then the lowest cap label limits it. Applied overrides are
+// recorded in result.Overrides.
+func applyOverrides(result *Result) {
+	var force, limit *ResultOverride
+	for _, c := range result.Commits {
+		for _, p := range c.Pulls {
+			for _, label := range p.OverrideLabels {
+				kind, level, ok := parseOverrideLabel(label)
+				if !ok {
+					continue
+				}
+				override := &ResultOverride{Label: label, Pull: p.Number, Commit: c.Sha, kind: kind, level: level}
+				switch {
+				case kind == overrideForce && (force == nil || level > force.level):
+					force = override
+				case kind == overrideCap && (limit == nil || level < limit.level):
+					limit = override
+				}
+			}
+		}
+	}
+	if force != nil {
+		if result.ChangeLevel < force.level {
+			result.ChangeLevel = force.level
+		}
+		force.ChangeLevel = result.ChangeLevel
+		result.Overrides = append(result.Overrides, *force)
+	}
+	if limit != nil {
+		if result.ChangeLevel > limit.level {
+			result.ChangeLevel = limit.level
+		}
+		limit.ChangeLevel = result.ChangeLevel
+		result.Overrides = append(result.Overrides, *limit)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_applyOverrides(t *testing.T) {
+	result := func(level changeLevel, pulls ...ResultPull) *Result {
+		var commits []ResultCommit
+		for i, p := range pulls {
+			commits = append(commits, ResultCommit{Sha: string(rune('a' + i)), Pulls: []ResultPull{p}})
+		}
+		return &Result{ChangeLevel: level, Commits: commits}
+	}
+
+	t.Run("no overrides", func(t *testing.T) {
+		got := result(changeLevelMinor, ResultPull{Number: 1})
+		applyOverrides(got)
+		require.Equal(t, changeLevelMinor, got.ChangeLevel)
+		require.Empty(t, got.Overrides)
+	})
+
+	t.Run("force", func(t *testing.T) {
+		got := result(changeLevelPatch,
+			ResultPull{Number: 1, OverrideLabels: []string{"semver:force-minor"}},
+			ResultPull{Number: 2, OverrideLabels: []string{"semver:force-major"}},
+		)
+		applyOverrides(got)
+		require.Equal(t, changeLevelMajor, got.ChangeLevel)
+		require.Equal(t, []ResultOverride{
+			{Label: "semver:force-major", Pull: 2, Commit: "b", ChangeLevel: changeLevelMajor, kind: overrideForce, level: changeLevelMajor},
+		}, got.Overrides)
+	})
+
+	t.Run("force doesn't lower the level", func(t *testing.T) {
+		got := result(changeLevelMajor,
+			ResultPull{Number: 1, OverrideLabels: []string{"semver:force-patch"}},
+		)
+		applyOverrides(got)
+		require.Equal(t, changeLevelMajor, got.ChangeLevel)
+		require.Equal(t, []ResultOverride{
+			{Label: "semver:force-patch", Pull: 1, Commit: "a", ChangeLevel: changeLevelMajor, kind: overrideForce, level: changeLevelPatch},
+		}, got.Overrides)
+	})
+
+	t.Run("cap", func(t *testing.T) {
+		got := result(changeLevelMajor,
+			ResultPull{Number: 1, OverrideLabels: []string{"semver:cap-patch"}},
+			ResultPull{Number: 2, OverrideLabels: []string{"semver:cap-minor"}},
+		)
+		applyOverrides(got)
+		require.Equal(t, changeLevelPatch, got.ChangeLevel)
+		require.Equal(t, []ResultOverride{
+			{Label: "semver:cap-patch", Pull: 1, Commit: "a", ChangeLevel: changeLevelPatch, kind: overrideCap, level: changeLevelPatch},
+		}, got.Overrides)
+	})
+
+	t.Run("cap after force", func(t *testing.T) {
+		got := result(changeLevelPatch,
+			ResultPull{Number: 1, OverrideLabels: []string{"semver:force-major", "semver:cap-minor"}},
+		)
+		applyOverrides(got)
+		require.Equal(t, changeLevelMinor, got.ChangeLevel)
+		require.Len(t, got.Overrides, 2)
+		require.Equal(t, changeLevelMajor, got.Overrides[0].ChangeLevel)
+		require.Equal(t, changeLevelMinor, got.Overrides[1].ChangeLevel)
+	})
+}
+
+func Test_parseOverrideLabel(t *testing.T) {
+	for _, label := range overrideLabels {
+		_, _, ok := parseOverrideLabel(label)
+		require.True(t, ok, label)
+	}
+	for _, label := range []string{"semver:major", "semver:force-none", "semver:cap-major", "semver:force-major-now"} {
+		_, _, ok := parseOverrideLabel(label)
+		require.False(t, ok, label)
+	}
+}
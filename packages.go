@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// PackageResult is the Result for one package declared in the config file.
+type PackageResult struct {
+	Package     string `json:"package"`
+	PreviousTag string `json:"previous_tag,omitempty"`
+	Result
+}
+
+// packageLabelLevel recognizes the labels recognized by levelFor plus labels in the package's namespace.
+// "semver:<namespace>:minor" is treated like "semver:minor".
+func packageLabelLevel(namespace string, levelFor labelLevelFunc) labelLevelFunc {
+	prefix := "semver:" + strings.ToLower(namespace) + ":"
+	return func(label string) (changeLevel, bool) {
+		if strings.HasPrefix(label, prefix) {
+			return levelFor("semver:" + strings.TrimPrefix(label, prefix))
+		}
+		return levelFor(label)
+	}
+}
+
+// anyLabelLevel recognizes a label when any of levelFuncs does.
+func anyLabelLevel(levelFuncs []labelLevelFunc) labelLevelFunc {
+	return func(label string) (changeLevel, bool) {
+		for _, fn := range levelFuncs {
+			level, ok := fn(label)
+			if ok {
+				return level, true
+			}
+		}
+		return changeLevelNoChange, false
+	}
+}
+
+// latestPackageTag returns the tag with the highest non-prerelease version among tags starting with prefix.
+func latestPackageTag(tags []string, prefix string) (string, *semver.Version) {
+	tag, v := latestTag(tags, prefix, semverScheme{}, nil)
+	if v == nil {
+		return "", nil
+	}
+	sv, _ := asSemver(v)
+	return tag, sv
+}
+
+// latestTag returns the tag with the highest non-prerelease version in scheme among tags starting with prefix. When
+// include is not nil, only versions it returns true for are considered.
+func latestTag(tags []string, prefix string, scheme versionScheme, include func(schemeVersion) bool) (string, schemeVersion) {
+	var latestTag string
+	var latest schemeVersion
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		v, err := scheme.parse(strings.TrimPrefix(tag, prefix))
+		if err != nil || v.prerelease() {
+			continue
+		}
+		if include != nil && !include(v) {
+			continue
+		}
+		if latest == nil || v.compare(latest) > 0 {
+			latestTag, latest = tag, v
+		}
+	}
+	return latestTag, latest
+}
+
+// filePackage returns the index of the package containing file or -1 when no package does. When package paths are
+// nested, the file belongs to the package with the longest path.
+func filePackage(packages []packageConfig, file string) int {
+	found := -1
+	for i := range packages {
+		path := packages[i].packagePath()
+		if path != "" && file != path && !strings.HasPrefix(file, path+"/") {
+			continue
+		}
+		if found == -1 || len(path) > len(packages[found].packagePath()) {
+			found = i
+		}
+	}
+	return found
+}
+
+// fetchCommitPackages returns the indexes of the packages each commit changes files in keyed by sha. It returns nil
+// when all packages are at the repository root because every commit belongs to all of them.
+func fetchCommitPackages(ctx context.Context, gh wrapper, owner, repo string, packages []packageConfig, commitShas []string) (map[string]map[int]bool, error) {
+	scoped := false
+	for i := range packages {
+		if packages[i].packagePath() != "" {
+			scoped = true
+		}
+	}
+	if !scoped {
+		return nil, nil
+	}
+	files := make([][]string, len(commitShas))
+	var err error
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	for i := range commitShas {
+		commitSha := commitShas[i]
+		wg.Add(1)
+		go func(idx int) {
+			var e error
+			files[idx], e = gh.ListCommitFiles(ctx, owner, repo, commitSha)
+			errLock.Lock()
+			err = errors.Join(err, e)
+			errLock.Unlock()
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[int]bool, len(commitShas))
+	for i, sha := range commitShas {
+		result[sha] = map[int]bool{}
+		for _, file := range files[i] {
+			if idx := filePackage(packages, file); idx != -1 {
+				result[sha][idx] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// nextPackages computes a Result for each package. Each package is compared from its latest release tag to
+// opts.head and only counts the commits that change files in its path, but pull requests are only fetched once per
+// commit. Packages without a release tag are compared from opts.base with opts.prevVersion (or 0.0.0) as the previous
+// version.
+func nextPackages(ctx context.Context, opts nextOptions, packages []packageConfig) ([]PackageResult, error) {
+	minBumpLevel, maxBumpLevel, err := bumpLimits(opts.minBump, opts.maxBump)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, err := splitRepo(opts.repo)
+	if err != nil {
+		return nil, err
+	}
+	fallbackVersion := opts.prevVersion
+	if fallbackVersion == "" {
+		fallbackVersion = "0.0.0"
+	}
+	fallback, err := semver.NewVersion(fallbackVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous version %q: %v", fallbackVersion, err)
+	}
+	tags, err := opts.gh.ListTags(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PackageResult, len(packages))
+	prevVersions := make([]*semver.Version, len(packages))
+	bases := make([]string, len(packages))
+	packageShas := make([][]string, len(packages))
+	compareStatuses := make([]string, len(packages))
+	levelFuncs := make([]labelLevelFunc, len(packages))
+	packageRules := make([]*pullRules, len(packages))
+	var allCommits []repoCommit
+	seen := map[string]bool{}
+	for i := range packages {
+		pkg := &packages[i]
+		results[i].Package = pkg.Name
+		base := opts.base
+		prevTag, prev := latestPackageTag(tags, pkg.tagPrefix())
+		if prev == nil {
+			prev = fallback
+		} else {
+			base = prevTag
+			results[i].PreviousTag = prevTag
+		}
+		prevVersions[i] = prev
+		bases[i] = base
+		levelFuncs[i] = packageLabelLevel(pkg.labelNamespace(), opts.rules.levelForLabel)
+		packageRules[i] = opts.rules.withLabelLevel(levelFuncs[i])
+		comp, err := opts.gh.CompareCommits(ctx, owner, repo, base, opts.head)
+		if err != nil {
+			return nil, err
+		}
+		err = checkCompareStatus(base, opts.head, comp.Status)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", pkg.Name, err)
+		}
+		compareStatuses[i] = comp.Status
+		packageShas[i] = commitShas(comp.Commits)
+		for _, c := range comp.Commits {
+			if !seen[c.Sha] {
+				seen[c.Sha] = true
+				allCommits = append(allCommits, c)
+			}
+		}
+	}
+
+	allShas := commitShas(allCommits)
+	commitPulls, err := fetchCommitPulls(ctx, opts.gh, owner, repo, allShas)
+	if err != nil {
+		return nil, err
+	}
+	cherryPicks, err := resolveBackports(ctx, opts.gh, owner, repo, allCommits, commitPulls)
+	if err != nil {
+		return nil, err
+	}
+	commitPackages, err := fetchCommitPackages(ctx, opts.gh, owner, repo, packages, allShas)
+	if err != nil {
+		return nil, err
+	}
+	err = checkMissingLabels(evaluateCommits(allShas, commitPulls, opts.rules.withLabelLevel(anyLabelLevel(levelFuncs))))
+	if err != nil {
+		return nil, err
+	}
+	for i := range packages {
+		shas := packageShas[i]
+		if commitPackages != nil {
+			shas = nil
+			for _, sha := range packageShas[i] {
+				if commitPackages[sha][i] {
+					shas = append(shas, sha)
+				}
+			}
+		}
+		commits := withCherryPicks(evaluateCommits(shas, commitPulls, packageRules[i]), cherryPicks)
+		result := newResult(semverVersion{prevVersions[i]}, commits)
+		result.CompareStatus = compareStatuses[i]
+		err = checkConflictingLabels(opts.conflictingLabels, packageRules[i], result)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", packages[i].Name, err)
+		}
+		err = applyAPIDiff(ctx, &opts, packages[i].Path, bases[i], result)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", packages[i].Name, err)
+		}
+		applyOverrides(result)
+		applyBump(result, semverVersion{prevVersions[i]}, minBumpLevel, maxBumpLevel)
+		err = checkExistingVersion(tags, packages[i].tagPrefix(), semverScheme{}, opts.autoSkip, result)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", packages[i].Name, err)
+		}
+		err = checkGoModule(ctx, opts.gh, owner, repo, opts.head, packages[i].Path, opts.goModule, result)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", packages[i].Name, err)
+		}
+		results[i].Result = *result
+	}
+	return results, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextPackages(t *testing.T) {
+	ctx := context.Background()
+
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha2 := "2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sha3 := "3aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	packages := []packageConfig{
+		{Name: "root"},
+		{Name: "api", Path: "api"},
+		{Name: "cli", Path: "cmd/cli", TagPrefix: "cli-", LabelNamespace: "command"},
+	}
+
+	t.Run("single scan", func(t *testing.T) {
+		gh := wrapperStub{
+			listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+				return []string{"v1.2.0", "v1.3.0-rc.1", "v1.1.0", "api/v0.4.0", "api/v0.3.0", "foo"}, nil
+			},
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				assert.Equal(t, sha3, head)
+				switch base {
+				case "v1.2.0":
+					return []string{sha1, sha2, sha3}, nil
+				case "api/v0.4.0":
+					return []string{sha2, sha3}, nil
+				case "first":
+					return []string{sha1, sha2, sha3}, nil
+				}
+				t.Errorf("unexpected base %q", base)
+				return nil, nil
+			},
+			listCommitFiles: func(ctx context.Context, owner, repo, sha string) ([]string, error) {
+				switch sha {
+				case sha1:
+					return []string{"api/api.go"}, nil
+				case sha2:
+					return []string{"cmd/cli/main.go"}, nil
+				case sha3:
+					return []string{"web/index.html", "api/doc.go"}, nil
+				}
+				t.Errorf("unexpected sha %q", sha)
+				return nil, nil
+			},
+			// each commit is only looked up once
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{
+					owner: "willabides", repo: "semver-next", sha: sha1,
+					result: []ResultPull{{Number: 1, Labels: []string{"semver:api:major"}}},
+				},
+				{
+					owner: "willabides", repo: "semver-next", sha: sha2,
+					result: []ResultPull{{Number: 2, Labels: []string{"semver:command:minor"}}},
+				},
+				{
+					owner: "willabides", repo: "semver-next", sha: sha3,
+					result: []ResultPull{{Number: 3, Labels: []string{"bug"}}},
+				},
+			}),
+		}
+		got, err := nextPackages(ctx, nextOptions{
+			repo: "willabides/semver-next",
+			base: "first",
+			head: sha3,
+			gh:   &gh,
+		}, packages)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+
+		require.Equal(t, "root", got[0].Package)
+		require.Equal(t, "v1.2.0", got[0].PreviousTag)
+		require.Equal(t, "1.2.1", got[0].NextVersion)
+		require.Len(t, got[0].Commits, 1)
+		require.Equal(t, sha3, got[0].Commits[0].Sha)
+
+		require.Equal(t, "api", got[1].Package)
+		require.Equal(t, "api/v0.4.0", got[1].PreviousTag)
+		require.Equal(t, "0.4.1", got[1].NextVersion)
+		require.Equal(t, []ResultCommit{
+			{
+				Sha:         sha3,
+				Pulls:       []ResultPull{{Number: 3, Labels: []string{"bug"}, ChangeLevel: changeLevelPatch}},
+				ChangeLevel: changeLevelPatch,
+			},
+		}, got[1].Commits)
+
+		require.Equal(t, "cli", got[2].Package)
+		require.Equal(t, "", got[2].PreviousTag)
+		require.Equal(t, "0.1.0", got[2].NextVersion)
+		require.Equal(t, changeLevelMinor, got[2].ChangeLevel)
+		require.Len(t, got[2].Commits, 1)
+		require.Equal(t, sha2, got[2].Commits[0].Sha)
+	})
+
+	t.Run("unnamespaced labels only apply to changed packages", func(t *testing.T) {
+		gh := wrapperStub{
+			listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+				return []string{"web/v1.0.0", "api/v2.0.0"}, nil
+			},
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				return []string{sha1, sha2}, nil
+			},
+			listCommitFiles: func(ctx context.Context, owner, repo, sha string) ([]string, error) {
+				if sha == sha1 {
+					return []string{"web/app.js"}, nil
+				}
+				return []string{"api/api.go", "README.md"}, nil
+			},
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{
+					owner: "willabides", repo: "semver-next", sha: sha1,
+					result: []ResultPull{{Number: 1, Labels: []string{"semver:minor"}}},
+				},
+				{
+					owner: "willabides", repo: "semver-next", sha: sha2,
+					result: []ResultPull{{Number: 2, Labels: []string{"bug"}}},
+				},
+			}),
+		}
+		got, err := nextPackages(ctx, nextOptions{
+			repo: "willabides/semver-next",
+			head: sha3,
+			gh:   &gh,
+		}, []packageConfig{
+			{Name: "web", Path: "web"},
+			{Name: "api", Path: "api/"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.1.0", got[0].NextVersion)
+		require.Equal(t, changeLevelMinor, got[0].ChangeLevel)
+		require.Equal(t, "2.0.1", got[1].NextVersion)
+		require.Equal(t, changeLevelPatch, got[1].ChangeLevel)
+	})
+
+	t.Run("missing labels", func(t *testing.T) {
+		gh := wrapperStub{
+			listTags: func(ctx context.Context, owner, repo string) ([]string, error) {
+				return []string{"v1.2.0"}, nil
+			},
+			compareCommits: func(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+				return []string{sha1}, nil
+			},
+			listPullRequestsWithCommit: mockListPullRequestsWithCommit(t, []listPullRequestsWithCommitCall{
+				{
+					owner: "willabides", repo: "semver-next", sha: sha1,
+					result: []ResultPull{{Number: 1, Labels: []string{"semver:other:major"}}},
+				},
+			}),
+		}
+		_, err := nextPackages(ctx, nextOptions{
+			repo: "willabides/semver-next",
+			base: "first",
+			head: sha3,
+			gh:   &gh,
+		}, packages[:1])
+		require.EqualError(t, err, "commits with no semver labels on associated PRs:\n"+sha1+" (#1)")
+	})
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Regexp matches PEP 440 versions including the alternate spellings the spec allows.
+// https://peps.python.org/pep-0440/#appendix-b-parsing-version-strings-with-regular-expressions
+var pep440Regexp = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>\d+)!)?` +
+	`(?P<release>\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_.]?(?P<pre_n>\d+)?)?` +
+	`(?:-(?P<post_n1>\d+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>\d+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>\d+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pep440PreLabels normalizes pre-release labels and orders them.
+var pep440PreLabels = map[string]string{
+	"a": "a", "alpha": "a",
+	"b": "b", "beta": "b",
+	"rc": "rc", "c": "rc", "pre": "rc", "preview": "rc",
+}
+
+var pep440PreOrder = map[string]int{"a": 0, "b": 1, "rc": 2}
+
+// pep440Scheme is Python package versioning as specified by PEP 440. Versions are output in normalized form. Bumps
+// change the first three release segments like semver and drop pre, post, dev and local segments. A patch bump of a
+// pre-release or dev release releases it without incrementing.
+type pep440Scheme struct{}
+
+type pep440Version struct {
+	epoch   int
+	release []int
+	preL    string // "a", "b", "rc" or "" when not a pre-release
+	preN    int
+	post    int // -1 when not a post-release
+	dev     int // -1 when not a dev release
+	local   string
+}
+
+func (pep440Scheme) parse(v string) (schemeVersion, error) {
+	match := pep440Regexp.FindStringSubmatch(v)
+	if match == nil {
+		return nil, fmt.Errorf("%q is not a PEP 440 version", v)
+	}
+	group := func(name string) string {
+		return match[pep440Regexp.SubexpIndex(name)]
+	}
+	number := func(s string) int {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	result := &pep440Version{
+		epoch: number(group("epoch")),
+		post:  -1,
+		dev:   -1,
+		local: strings.ToLower(group("local")),
+	}
+	for _, s := range strings.Split(group("release"), ".") {
+		result.release = append(result.release, number(s))
+	}
+	if l := group("pre_l"); l != "" {
+		result.preL = pep440PreLabels[strings.ToLower(l)]
+		result.preN = number(group("pre_n"))
+	}
+	switch {
+	case group("post_n1") != "":
+		result.post = number(group("post_n1"))
+	case group("post_l") != "":
+		result.post = number(group("post_n2"))
+	}
+	if group("dev_l") != "" {
+		result.dev = number(group("dev_n"))
+	}
+	return result, nil
+}
+
+func (v *pep440Version) bump(level changeLevel) schemeVersion {
+	var idx int
+	switch level {
+	case changeLevelMajor:
+		idx = 0
+	case changeLevelMinor:
+		idx = 1
+	case changeLevelPatch:
+		idx = 2
+	default:
+		return v
+	}
+	release := make([]int, len(v.release))
+	copy(release, v.release)
+	for len(release) < 3 {
+		release = append(release, 0)
+	}
+	next := &pep440Version{epoch: v.epoch, release: release, post: -1, dev: -1}
+	if level == changeLevelPatch && v.prerelease() && v.post == -1 {
+		return next
+	}
+	release[idx]++
+	for i := idx + 1; i < len(release); i++ {
+		release[i] = 0
+	}
+	return next
+}
+
+// key returns the values PEP 440 orders versions by after the epoch and release: the pre-release phase and number,
+// the post-release number and the dev release number.
+func (v *pep440Version) key() [4]int {
+	// a dev release without a pre or post segment sorts before pre-releases, and final releases sort after them
+	phase := len(pep440PreOrder)
+	switch {
+	case v.preL != "":
+		phase = pep440PreOrder[v.preL]
+	case v.post == -1 && v.dev != -1:
+		phase = -1
+	}
+	// dev releases sort before the release they precede
+	dev := v.dev
+	if dev == -1 {
+		dev = math.MaxInt32
+	}
+	return [4]int{phase, v.preN, v.post, dev}
+}
+
+func (v *pep440Version) compare(other schemeVersion) int {
+	o := other.(*pep440Version)
+	if c := compareInts(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(v.release) || i < len(o.release); i++ {
+		var a, b int
+		if i < len(v.release) {
+			a = v.release[i]
+		}
+		if i < len(o.release) {
+			b = o.release[i]
+		}
+		if c := compareInts(a, b); c != 0 {
+			return c
+		}
+	}
+	vk, ok := v.key(), o.key()
+	for i := range vk {
+		if c := compareInts(vk[i], ok[i]); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(v.local, o.local)
+}
+
+func (v *pep440Version) prerelease() bool {
+	return v.preL != "" || v.dev != -1
+}
+
+func (v *pep440Version) String() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+	for i, n := range v.release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	if v.preL != "" {
+		fmt.Fprintf(&b, "%s%d", v.preL, v.preN)
+	}
+	if v.post != -1 {
+		fmt.Fprintf(&b, ".post%d", v.post)
+	}
+	if v.dev != -1 {
+		fmt.Fprintf(&b, ".dev%d", v.dev)
+	}
+	if v.local != "" {
+		fmt.Fprintf(&b, "+%s", v.local)
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// checkPrevVersion checks that the tag for prev points at the same commit as base. A mismatch is added to
+// result.Warnings or returned as an error when strict is true. It can't be checked when no tag in tags has prev's
+// version, as with release tags that don't follow the scheme, so that isn't reported.
+func checkPrevVersion(ctx context.Context, gh wrapper, owner, repo, base string, tags []string, scheme versionScheme, prev schemeVersion, strict bool, result *Result) error {
+	msg, err := prevVersionMismatch(ctx, gh, owner, repo, base, tags, scheme, prev)
+	if err != nil || msg == "" {
+		return err
+	}
+	if strict {
+		return errors.New(msg)
+	}
+	result.Warnings = append(result.Warnings, msg)
+	return nil
+}
+
+// prevVersionMismatch describes how the tag for prev differs from base. It returns "" when they point at the same
+// commit or prev has no tag.
+func prevVersionMismatch(ctx context.Context, gh wrapper, owner, repo, base string, tags []string, scheme versionScheme, prev schemeVersion) (string, error) {
+	var tag string
+	for _, t := range tags {
+		v, err := scheme.parse(t)
+		if err == nil && v.compare(prev) == 0 {
+			tag = t
+			break
+		}
+	}
+	if tag == "" || tag == base {
+		return "", nil
+	}
+	tagSha, err := gh.GetCommitSha(ctx, owner, repo, tag)
+	if err != nil {
+		return "", err
+	}
+	baseSha, err := gh.GetCommitSha(ctx, owner, repo, base)
+	if err != nil {
+		return "", err
+	}
+	if tagSha == baseSha {
+		return "", nil
+	}
+	return fmt.Sprintf(
+		"previous version %s is tagged %s at commit %s, but the previous ref %s is commit %s",
+		prev, tag, shortSha(tagSha), base, shortSha(baseSha),
+	), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_next_prevVersion(t *testing.T) {
+	ctx := context.Background()
+	sha1 := "1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	refShas := map[string]string{
+		"v1.2.0":   "7aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"release1": "7aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"v1.3.0":   "8aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}
+	stub := func() *wrapperStub {
+		gh := labeledPullStub(t, sha1, "bug").withTags("v1.2.0", "v1.3.0")
+		gh.getCommitSha = func(ctx context.Context, owner, repo, ref string) (string, error) {
+			return refShas[ref], nil
+		}
+		return gh
+	}
+	run := func(base, prevVersion string, strict bool) (*Result, error) {
+		return next(ctx, nextOptions{
+			gh:          stub(),
+			repo:        "willabides/semver-next",
+			base:        base,
+			head:        "main",
+			prevVersion: prevVersion,
+			strict:      strict,
+		})
+	}
+
+	t.Run("same commit", func(t *testing.T) {
+		got, err := run("release1", "1.2.0", true)
+		require.NoError(t, err)
+		require.Empty(t, got.Warnings)
+		require.Equal(t, "1.2.1", got.NextVersion)
+	})
+
+	t.Run("different commit", func(t *testing.T) {
+		got, err := run("release1", "1.3.0", false)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"previous version 1.3.0 is tagged v1.3.0 at commit 8aaaaaa, but the previous ref release1 is commit 7aaaaaa",
+		}, got.Warnings)
+		require.Equal(t, "1.3.1", got.NextVersion)
+	})
+
+	t.Run("different commit strict", func(t *testing.T) {
+		_, err := run("release1", "1.3.0", true)
+		require.EqualError(t, err, "previous version 1.3.0 is tagged v1.3.0 at commit 8aaaaaa, but the previous ref release1 is commit 7aaaaaa")
+	})
+
+	t.Run("no tag", func(t *testing.T) {
+		got, err := run("release1", "1.1.0", true)
+		require.NoError(t, err)
+		require.Empty(t, got.Warnings)
+	})
+
+	t.Run("tags not in scheme", func(t *testing.T) {
+		gh := stub().withTags("release-2023-01", "release-2023-02")
+		got, err := next(ctx, nextOptions{
+			gh:          gh,
+			repo:        "willabides/semver-next",
+			base:        "release-2023-02",
+			head:        "main",
+			prevVersion: "1.2.0",
+			strict:      true,
+		})
+		require.NoError(t, err)
+		require.Empty(t, got.Warnings)
+		require.Equal(t, "1.2.1", got.NextVersion)
+	})
+
+	t.Run("tags are listed once", func(t *testing.T) {
+		gh := stub()
+		listTags := gh.listTags
+		calls := 0
+		gh.listTags = func(ctx context.Context, owner, repo string) ([]string, error) {
+			calls++
+			return listTags(ctx, owner, repo)
+		}
+		_, err := next(ctx, nextOptions{
+			gh:          gh,
+			repo:        "willabides/semver-next",
+			base:        "release1",
+			head:        "main",
+			prevVersion: "1.3.0",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+)
+
+// Ways to combine the change levels of a pull request's labels and conventional commit title.
+const (
+	titleModeLabelFirst = "label-first"
+	titleModeTitleFirst = "title-first"
+	titleModeMax        = "max"
+)
+
+// pullRules determine the change level of pull requests. A nil *pullRules uses the default labels.
+type pullRules struct {
+	// labelLevel recognizes labels. Defaults to defaultLabelLevel.
+	labelLevel labelLevelFunc
+
+	// titleMode is how conventional commit titles are combined with labels. Titles are ignored when empty.
+	titleMode string
+
+	// body determines a change level and release note from pull request bodies. Bodies are ignored when nil.
+	body *bodyRules
+}
+
+func (r *pullRules) levelForLabel(label string) (changeLevel, bool) {
+	if r == nil || r.labelLevel == nil {
+		return defaultLabelLevel(label)
+	}
+	return r.labelLevel(label)
+}
+
+// withLabelLevel returns a copy of r that recognizes labels with labelLevel.
+func (r *pullRules) withLabelLevel(labelLevel labelLevelFunc) *pullRules {
+	var result pullRules
+	if r != nil {
+		result = *r
+	}
+	result.labelLevel = labelLevel
+	return &result
+}
+
+// evaluate returns a copy of pulls with Labels filtered to recognized labels and ChangeLevel set.
+func (r *pullRules) evaluate(pulls []ResultPull) []ResultPull {
+	if pulls == nil {
+		return nil
+	}
+	result := make([]ResultPull, len(pulls))
+	for i, pull := range pulls {
+		result[i] = r.evaluatePull(pull)
+	}
+	return result
+}
+
+// evaluatePull sets pull's level from its labels, then applies the title according to titleMode. The body's level only
+// raises the result.
+func (r *pullRules) evaluatePull(pull ResultPull) ResultPull {
+	labelLevel := changeLevelNoChange
+	labels := make([]string, 0, len(pull.Labels))
+	pull.OverrideLabels = nil
+	for _, l := range pull.Labels {
+		l = strings.ToLower(l)
+		if _, _, ok := parseOverrideLabel(l); ok {
+			pull.OverrideLabels = append(pull.OverrideLabels, l)
+			continue
+		}
+		level, ok := r.levelForLabel(l)
+		if !ok {
+			continue
+		}
+		labels = append(labels, l)
+		if level > labelLevel {
+			labelLevel = level
+		}
+	}
+	pull.Labels = labels
+	pull.ChangeLevel = labelLevel
+	pull.TitleChangeLevel = nil
+	pull.BodyChangeLevel = nil
+	if r == nil {
+		return pull
+	}
+	r.applyTitle(&pull)
+	if r.body != nil {
+		bodyLevel, ok := r.body.level(pull.Body)
+		if ok {
+			pull.BodyChangeLevel = &bodyLevel
+			if bodyLevel > pull.ChangeLevel {
+				pull.ChangeLevel = bodyLevel
+			}
+		}
+		pull.ReleaseNote = r.body.releaseNote(pull.Body)
+	}
+	return pull
+}
+
+func (r *pullRules) applyTitle(pull *ResultPull) {
+	if r.titleMode == "" {
+		return
+	}
+	titleLevel, ok := conventionalTitleLevel(pull.Title)
+	if !ok {
+		return
+	}
+	pull.TitleChangeLevel = &titleLevel
+	switch r.titleMode {
+	case titleModeLabelFirst:
+		if len(pull.Labels) == 0 {
+			pull.ChangeLevel = titleLevel
+		}
+	case titleModeTitleFirst:
+		pull.ChangeLevel = titleLevel
+	case titleModeMax:
+		if titleLevel > pull.ChangeLevel {
+			pull.ChangeLevel = titleLevel
+		}
+	}
+}
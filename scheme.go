@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// versionScheme parses the versions of a versioning scheme such as semver, CalVer or PEP 440.
+type versionScheme interface {
+	parse(v string) (schemeVersion, error)
+}
+
+// schemeVersion is a version in a versionScheme. String formats the version.
+type schemeVersion interface {
+	// bump returns the version following a release with a change at level. It returns the same version for
+	// changeLevelNoChange.
+	bump(level changeLevel) schemeVersion
+
+	// compare returns -1, 0 or 1 when the version is lower than, equal to or higher than other. other must be from
+	// the same scheme.
+	compare(other schemeVersion) int
+
+	// prerelease returns true for versions that aren't final releases.
+	prerelease() bool
+
+	String() string
+}
+
+// datedScheme is a versionScheme whose next version depends on the date of the release.
+type datedScheme interface {
+	versionScheme
+
+	// at returns the scheme for a release made at t.
+	at(t time.Time) versionScheme
+}
+
+const (
+	schemeSemver   = "semver"
+	schemeCalver   = "calver"
+	schemePEP440   = "pep440"
+	schemeFourPart = "four-part"
+)
+
+// parseVersionScheme parses the value of --scheme. It is "semver", "pep440", "four-part" or "calver:<format>".
+func parseVersionScheme(value string) (versionScheme, error) {
+	name, arg, _ := strings.Cut(value, ":")
+	switch name {
+	case "", schemeSemver:
+		if arg != "" {
+			return nil, fmt.Errorf("invalid version scheme %q", value)
+		}
+		return semverScheme{}, nil
+	case schemeCalver:
+		return newCalverScheme(arg, nil)
+	case schemePEP440, schemeFourPart:
+		if arg != "" {
+			return nil, fmt.Errorf("invalid version scheme %q", value)
+		}
+		if name == schemePEP440 {
+			return pep440Scheme{}, nil
+		}
+		return fourPartScheme{}, nil
+	default:
+		return nil, fmt.Errorf("invalid version scheme %q", value)
+	}
+}
+
+// semverScheme is semantic versioning. It is the default versionScheme.
+type semverScheme struct{}
+
+func (semverScheme) parse(v string) (schemeVersion, error) {
+	sv, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, err
+	}
+	return semverVersion{sv}, nil
+}
+
+type semverVersion struct {
+	*semver.Version
+}
+
+func (v semverVersion) bump(level changeLevel) schemeVersion {
+	var next semver.Version
+	switch level {
+	case changeLevelPatch:
+		next = v.IncPatch()
+	case changeLevelMinor:
+		next = v.IncMinor()
+	case changeLevelMajor:
+		next = v.IncMajor()
+	default:
+		return v
+	}
+	return semverVersion{&next}
+}
+
+func (v semverVersion) compare(other schemeVersion) int {
+	return v.Compare(other.(semverVersion).Version)
+}
+
+func (v semverVersion) prerelease() bool {
+	return v.Prerelease() != ""
+}
+
+// compareInts compares a and b like strings.Compare.
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// asSemver returns v's semver version when v is from semverScheme.
+func asSemver(v schemeVersion) (*semver.Version, bool) {
+	sv, ok := v.(semverVersion)
+	if !ok {
+		return nil, false
+	}
+	return sv.Version, true
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pep440Scheme(t *testing.T) {
+	scheme := pep440Scheme{}
+
+	t.Run("normalize", func(t *testing.T) {
+		for input, want := range map[string]string{
+			"1.2.3":            "1.2.3",
+			"v1.2":             "1.2",
+			"1.2.3-RC.1":       "1.2.3rc1",
+			"1.2.3alpha2":      "1.2.3a2",
+			"1.2.3-1":          "1.2.3.post1",
+			"1.2.3.rev2":       "1.2.3.post2",
+			"1!2.0.0.dev3":     "1!2.0.0.dev3",
+			"1.0+Ubuntu.1":     "1.0+ubuntu.1",
+			"2.0b1.post2.dev3": "2.0b1.post2.dev3",
+		} {
+			v, err := scheme.parse(input)
+			require.NoError(t, err, input)
+			require.Equal(t, want, v.String(), input)
+		}
+		for _, input := range []string{"", "1.2.x", "one", "1.2.3-foo"} {
+			_, err := scheme.parse(input)
+			require.Error(t, err, input)
+		}
+	})
+
+	t.Run("bump", func(t *testing.T) {
+		for _, td := range []struct {
+			prev  string
+			level changeLevel
+			want  string
+		}{
+			{prev: "1.2.3", level: changeLevelPatch, want: "1.2.4"},
+			{prev: "1.2.3", level: changeLevelMinor, want: "1.3.0"},
+			{prev: "1.2.3", level: changeLevelMajor, want: "2.0.0"},
+			{prev: "1.2", level: changeLevelPatch, want: "1.2.1"},
+			{prev: "1.2.3.post1", level: changeLevelPatch, want: "1.2.4"},
+			{prev: "1.3.0rc1", level: changeLevelPatch, want: "1.3.0"},
+			{prev: "1.3.0rc1", level: changeLevelMinor, want: "1.4.0"},
+			{prev: "2!1.2.3+local", level: changeLevelMinor, want: "2!1.3.0"},
+			{prev: "1.2.3.dev1", level: changeLevelNoChange, want: "1.2.3.dev1"},
+		} {
+			v, err := scheme.parse(td.prev)
+			require.NoError(t, err)
+			require.Equal(t, td.want, v.bump(td.level).String(), "%s %s", td.prev, td.level)
+		}
+	})
+
+	t.Run("compare", func(t *testing.T) {
+		// in ascending order from the examples in PEP 440
+		ordered := []string{
+			"1.0.dev456", "1.0a1", "1.0a2.dev456", "1.0a12.dev456", "1.0a12", "1.0b1.dev456", "1.0b2",
+			"1.0b2.post345.dev456", "1.0b2.post345", "1.0rc1.dev456", "1.0rc1", "1.0", "1.0+abc.5", "1.0.post456.dev34",
+			"1.0.post456", "1.0.15", "1.1.dev1", "1!0.1",
+		}
+		for i := 1; i < len(ordered); i++ {
+			a, err := scheme.parse(ordered[i-1])
+			require.NoError(t, err)
+			b, err := scheme.parse(ordered[i])
+			require.NoError(t, err)
+			require.Equal(t, -1, a.compare(b), "%s < %s", ordered[i-1], ordered[i])
+			require.Equal(t, 1, b.compare(a), "%s > %s", ordered[i], ordered[i-1])
+		}
+		a, err := scheme.parse("1.0")
+		require.NoError(t, err)
+		b, err := scheme.parse("1.0.0")
+		require.NoError(t, err)
+		require.Equal(t, 0, a.compare(b))
+	})
+}
+
+func Test_fourPartScheme(t *testing.T) {
+	scheme := fourPartScheme{}
+	v, err := scheme.parse("v1.2.3.4")
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3.4", v.String())
+	require.Equal(t, "1.2.3.4", v.bump(changeLevelNoChange).String())
+	require.Equal(t, "1.2.4.0", v.bump(changeLevelPatch).String())
+	require.Equal(t, "1.3.0.0", v.bump(changeLevelMinor).String())
+	require.Equal(t, "2.0.0.0", v.bump(changeLevelMajor).String())
+	require.Equal(t, -1, v.compare(v.bump(changeLevelPatch)))
+	require.Equal(t, 0, v.compare(v))
+
+	for _, input := range []string{"1.2.3", "1.2.3.4.5", "1.2.3.x", "1.2.3.-4"} {
+		_, err = scheme.parse(input)
+		require.Error(t, err, input)
+	}
+}
+
+func Test_latestTag(t *testing.T) {
+	tags := []string{"v1.0.0", "1.2", "1.10.0rc1", "1.9.0", "not-a-version"}
+	tag, v := latestTag(tags, "", pep440Scheme{}, nil)
+	require.Equal(t, "1.9.0", tag)
+	require.Equal(t, "1.9.0", v.String())
+
+	tag, _ = latestTag([]string{"v1.2.3.4", "v1.10.0.0", "v1.9.9.9"}, "v", fourPartScheme{}, nil)
+	require.Equal(t, "v1.10.0.0", tag)
+}
+
+func Test_parseVersionScheme(t *testing.T) {
+	scheme, err := parseVersionScheme("semver")
+	require.NoError(t, err)
+	require.Equal(t, semverScheme{}, scheme)
+	scheme, err = parseVersionScheme("calver:YY.0M.MICRO")
+	require.NoError(t, err)
+	require.IsType(t, &calverScheme{}, scheme)
+	scheme, err = parseVersionScheme("pep440")
+	require.NoError(t, err)
+	require.Equal(t, pep440Scheme{}, scheme)
+	scheme, err = parseVersionScheme("four-part")
+	require.NoError(t, err)
+	require.Equal(t, fourPartScheme{}, scheme)
+	_, err = parseVersionScheme("semver:foo")
+	require.Error(t, err)
+	_, err = parseVersionScheme("zerover")
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+)
+
+type serveCmd struct {
+	Addr          string `kong:"default=:8080,help=${addr_help}"`
+	WebhookSecret string `kong:"required,env=SEMVER_NEXT_WEBHOOK_SECRET,help=${webhook_secret_help}"`
+	Comment       bool   `kong:"help=${serve_comment_help}"`
+	CheckRun      bool   `kong:"default=true,negatable,help=${serve_check_run_help}"`
+}
+
+func (c *serveCmd) Run(gh wrapper, cfg *config) error {
+	if !c.Comment && !c.CheckRun {
+		return fmt.Errorf("at least one of --comment or --check-run is required")
+	}
+	server := &http.Server{
+		Addr: c.Addr,
+		Handler: &webhookHandler{
+			gh:                  gh,
+			secret:              []byte(c.WebhookSecret),
+			rules:               cfg.pullRules(),
+			comment:             c.Comment,
+			checkRun:            c.CheckRun,
+			maintenanceBranches: cfg.maintenanceBranches,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("listening on %s", c.Addr)
+	return server.ListenAndServe()
+}
+
+// webhookActions are the pull_request webhook actions that can change a pull request's labels, title, body or head
+// commit.
+var webhookActions = map[string]bool{
+	"opened":      true,
+	"edited":      true,
+	"reopened":    true,
+	"synchronize": true,
+	"labeled":     true,
+	"unlabeled":   true,
+}
+
+// webhookTimeout limits how long a delivery is handled after it has been acknowledged. GitHub only waits 10 seconds for
+// a response, so deliveries are handled after responding.
+const webhookTimeout = 5 * time.Minute
+
+// webhookHandler handles GitHub pull_request webhooks by validating the pull request's labels and posting a check
+// run and/or comment.
+type webhookHandler struct {
+	gh       wrapper
+	secret   []byte
+	rules    *pullRules
+	comment  bool
+	checkRun bool
+
+	// maintenanceBranches detect maintenance branches. nil uses defaultMaintenanceBranches.
+	maintenanceBranches []*regexp.Regexp
+
+	// wg tracks the deliveries being handled after their response.
+	wg sync.WaitGroup
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := github.ValidatePayload(r, h.secret)
+	if err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pullEvent, ok := event.(*github.PullRequestEvent)
+	if !ok || !webhookActions[pullEvent.GetAction()] || pullEvent.GetPullRequest().GetState() != "open" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	deliveryID := github.DeliveryID(r)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+		err := h.handlePull(ctx, pullEvent.GetRepo().GetFullName(), pullEvent.GetNumber())
+		if err != nil {
+			log.Printf("delivery %s: %v", deliveryID, err)
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *webhookHandler) handlePull(ctx context.Context, repoName string, number int) error {
+	res, err := predictPull(ctx, pullOptions{
+		gh:                  h.gh,
+		repo:                repoName,
+		number:              number,
+		rules:               h.rules,
+		maintenanceBranches: h.maintenanceBranches,
+	})
+	if err != nil {
+		return err
+	}
+	owner, repo, err := splitRepo(repoName)
+	if err != nil {
+		return err
+	}
+	if h.checkRun {
+		err = errors.Join(err, h.gh.CreateCheckRun(ctx, owner, repo, pullCheckRun(res)))
+	}
+	if h.comment {
+		err = errors.Join(err, upsertPullComment(ctx, h.gh, owner, repo, number, pullComment(res)))
+	}
+	return err
+}
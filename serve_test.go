@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub is a minimal stand-in for the GitHub API that records the check runs and comments it receives. When
+// unblock is set, requests for the pull request wait until it is closed.
+type fakeGitHub struct {
+	mu        sync.Mutex
+	labels    []string
+	unblock   chan struct{}
+	checkRuns []github.CreateCheckRunOptions
+	comments  []string
+}
+
+func (f *fakeGitHub) handler(t *testing.T) http.Handler {
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/willabides/semver-next/pulls/12", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		unblock := f.unblock
+		f.mu.Unlock()
+		if unblock != nil {
+			<-unblock
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		labels := make([]map[string]string, len(f.labels))
+		for i, l := range f.labels {
+			labels[i] = map[string]string{"name": l}
+		}
+		writeJSON(w, map[string]any{
+			"number": 12,
+			"state":  "open",
+			"labels": labels,
+			"base":   map[string]string{"ref": "main", "sha": "base"},
+			"head":   map[string]string{"ref": "feature", "sha": "headsha"},
+		})
+	})
+	mux.HandleFunc("/repos/willabides/semver-next/tags", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]string{{"name": "v1.2.0"}, {"name": "v1.1.0"}})
+	})
+	mux.HandleFunc("/repos/willabides/semver-next/compare/v1.2.0...main", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"commits": []map[string]string{{"sha": "merged"}}})
+	})
+	mux.HandleFunc("/repos/willabides/semver-next/commits/merged/pulls", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"number": 1, "merged_at": "2023-01-01T00:00:00Z", "labels": []map[string]string{{"name": "bug"}}},
+		})
+	})
+	mux.HandleFunc("/repos/willabides/semver-next/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		var opts github.CreateCheckRunOptions
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		f.mu.Lock()
+		f.checkRuns = append(f.checkRuns, opts)
+		f.mu.Unlock()
+		writeJSON(w, map[string]any{"id": 1})
+	})
+	mux.HandleFunc("/repos/willabides/semver-next/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Method == http.MethodPost {
+			var comment github.IssueComment
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+			f.comments = append(f.comments, comment.GetBody())
+			writeJSON(w, map[string]any{"id": len(f.comments)})
+			return
+		}
+		writeJSON(w, []any{})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.NotFound(w, r)
+	})
+	return mux
+}
+
+func Test_webhookHandler(t *testing.T) {
+	fake := &fakeGitHub{}
+	ghServer := httptest.NewServer(fake.handler(t))
+	t.Cleanup(ghServer.Close)
+	client := github.NewClient(nil)
+	var err error
+	client.BaseURL, err = url.Parse(ghServer.URL + "/")
+	require.NoError(t, err)
+
+	secret := []byte("shh")
+	handler := &webhookHandler{
+		gh:       &ghWrapper{client: client},
+		secret:   secret,
+		checkRun: true,
+		comment:  true,
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	post := func(t *testing.T, event string, payload any, key []byte) *http.Response {
+		t.Helper()
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(github.EventTypeHeader, event)
+		req.Header.Set(github.SHA256SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	pullEvent := func(action string) map[string]any {
+		return map[string]any{
+			"action":       action,
+			"number":       12,
+			"pull_request": map[string]any{"number": 12, "state": "open"},
+			"repository":   map[string]any{"full_name": "willabides/semver-next"},
+		}
+	}
+
+	t.Run("invalid signature", func(t *testing.T) {
+		resp := post(t, "pull_request", pullEvent("labeled"), []byte("wrong"))
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Empty(t, fake.checkRuns)
+	})
+
+	t.Run("ignored event", func(t *testing.T) {
+		resp := post(t, "pull_request", pullEvent("closed"), secret)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp = post(t, "issues", map[string]any{"action": "opened"}, secret)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Empty(t, fake.checkRuns)
+	})
+
+	t.Run("missing labels", func(t *testing.T) {
+		fake.labels = []string{"documentation"}
+		resp := post(t, "pull_request", pullEvent("opened"), secret)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		handler.wg.Wait()
+		require.Len(t, fake.checkRuns, 1)
+		require.Equal(t, "semver-next", fake.checkRuns[0].Name)
+		require.Equal(t, "headsha", fake.checkRuns[0].HeadSHA)
+		require.Equal(t, "failure", fake.checkRuns[0].GetConclusion())
+		require.Len(t, fake.comments, 1)
+		require.Contains(t, fake.comments[0], pullCommentMarker)
+	})
+
+	t.Run("labeled", func(t *testing.T) {
+		fake.labels = []string{"enhancement"}
+		resp := post(t, "pull_request", pullEvent("labeled"), secret)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		handler.wg.Wait()
+		require.Len(t, fake.checkRuns, 2)
+		require.Equal(t, "success", fake.checkRuns[1].GetConclusion())
+		require.Equal(t, "minor change, next version 1.3.0", fake.checkRuns[1].GetOutput().GetTitle())
+	})
+
+	t.Run("responds before handling", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.unblock = make(chan struct{})
+		fake.mu.Unlock()
+		resp := post(t, "pull_request", pullEvent("synchronize"), secret)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		fake.mu.Lock()
+		require.Len(t, fake.checkRuns, 2)
+		close(fake.unblock)
+		fake.unblock = nil
+		fake.mu.Unlock()
+		handler.wg.Wait()
+		require.Len(t, fake.checkRuns, 3)
+	})
+
+	t.Run("edited", func(t *testing.T) {
+		resp := post(t, "pull_request", pullEvent("edited"), secret)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		handler.wg.Wait()
+		require.Len(t, fake.checkRuns, 4)
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// conventionalTitleRegexp matches the "type(scope)!: " prefix of a conventional commit message.
+var conventionalTitleRegexp = regexp.MustCompile(`^\s*([a-zA-Z]+)(\([^)]*\))?(!)?:\s`)
+
+// conventionalTypeLevels maps conventional commit types to change levels.
+var conventionalTypeLevels = map[string]changeLevel{
+	"feat":     changeLevelMinor,
+	"fix":      changeLevelPatch,
+	"perf":     changeLevelPatch,
+	"build":    changeLevelNoChange,
+	"chore":    changeLevelNoChange,
+	"ci":       changeLevelNoChange,
+	"docs":     changeLevelNoChange,
+	"refactor": changeLevelNoChange,
+	"revert":   changeLevelNoChange,
+	"style":    changeLevelNoChange,
+	"test":     changeLevelNoChange,
+}
+
+// conventionalTitleLevel returns the change level of a pull request title in conventional commit format such as
+// "feat(api)!: remove Foo". It returns false for titles that aren't in that format or have an unknown type.
+func conventionalTitleLevel(title string) (changeLevel, bool) {
+	match := conventionalTitleRegexp.FindStringSubmatch(title)
+	if match == nil {
+		return changeLevelNoChange, false
+	}
+	level, ok := conventionalTypeLevels[strings.ToLower(match[1])]
+	if !ok {
+		return changeLevelNoChange, false
+	}
+	if match[3] == "!" {
+		return changeLevelMajor, true
+	}
+	return level, true
+}
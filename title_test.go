@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_conventionalTitleLevel(t *testing.T) {
+	for _, td := range []struct {
+		title string
+		level changeLevel
+		ok    bool
+	}{
+		{title: "feat: add Foo", level: changeLevelMinor, ok: true},
+		{title: "feat(api)!: remove Foo", level: changeLevelMajor, ok: true},
+		{title: "fix!: change Foo", level: changeLevelMajor, ok: true},
+		{title: "Fix(cli): handle empty input", level: changeLevelPatch, ok: true},
+		{title: "docs: typo", level: changeLevelNoChange, ok: true},
+		{title: "add Foo"},
+		{title: "feat:no space"},
+		{title: "wip: something"},
+	} {
+		t.Run(td.title, func(t *testing.T) {
+			level, ok := conventionalTitleLevel(td.title)
+			require.Equal(t, td.ok, ok)
+			require.Equal(t, td.level, level)
+		})
+	}
+}
+
+func Test_pullRules_evaluatePull(t *testing.T) {
+	minor := changeLevelMinor
+	pull := ResultPull{Number: 1, Title: "feat: add Foo", Labels: []string{"bug"}}
+	unlabeled := ResultPull{Number: 2, Title: "feat: add Foo", Labels: []string{"documentation"}}
+	patchTitle := ResultPull{Number: 3, Title: "fix: Foo", Labels: []string{"breaking"}}
+
+	t.Run("titles ignored by default", func(t *testing.T) {
+		var rules *pullRules
+		got := rules.evaluatePull(unlabeled)
+		require.Equal(t, ResultPull{Number: 2, Title: "feat: add Foo", Labels: []string{}}, got)
+		require.False(t, got.hasLevel())
+	})
+
+	t.Run(titleModeLabelFirst, func(t *testing.T) {
+		rules := &pullRules{titleMode: titleModeLabelFirst}
+		require.Equal(t, changeLevelPatch, rules.evaluatePull(pull).ChangeLevel)
+		got := rules.evaluatePull(unlabeled)
+		require.Equal(t, ResultPull{
+			Number: 2, Title: "feat: add Foo", Labels: []string{},
+			ChangeLevel: changeLevelMinor, TitleChangeLevel: &minor,
+		}, got)
+		require.True(t, got.hasLevel())
+	})
+
+	t.Run(titleModeTitleFirst, func(t *testing.T) {
+		rules := &pullRules{titleMode: titleModeTitleFirst}
+		require.Equal(t, changeLevelMinor, rules.evaluatePull(pull).ChangeLevel)
+		require.Equal(t, changeLevelPatch, rules.evaluatePull(patchTitle).ChangeLevel)
+	})
+
+	t.Run(titleModeMax, func(t *testing.T) {
+		rules := &pullRules{titleMode: titleModeMax}
+		require.Equal(t, changeLevelMinor, rules.evaluatePull(pull).ChangeLevel)
+		require.Equal(t, changeLevelMajor, rules.evaluatePull(patchTitle).ChangeLevel)
+	})
+}